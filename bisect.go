@@ -0,0 +1,91 @@
+package ben
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/drish/ben/bisect"
+	"github.com/drish/ben/builders"
+	"github.com/drish/ben/git"
+	"github.com/drish/ben/reporter"
+	"github.com/drish/ben/utils"
+	"github.com/pkg/errors"
+)
+
+// BisectOptions holds the command line options of a bisect
+type BisectOptions struct {
+	Good        string  // revision where the benchmark is fast
+	Bad         string  // revision where the benchmark is slow
+	Benchmark   string  // benchmark name, ie: BenchmarkFib10
+	Unit        string  // benchmark metric unit, ie: ns/op
+	Threshold   float64 // slowdown against the good revision that makes a commit bad
+	Environment int     // index of the environment benchmarks run on
+	Display     bool    // display benchmark results to stdout
+}
+
+// Bisect binary searches the commits between the good and bad revisions
+// for the first one where the benchmark is slower than on the good revision
+func (r *Runner) Bisect(ctx context.Context, opts BisectOptions) (bisect.Result, error) {
+
+	utils.Welcome()
+
+	if opts.Environment < 0 || opts.Environment >= len(r.config.Environments) {
+		return bisect.Result{}, errors.Errorf("environment %d doesn't exist", opts.Environment)
+	}
+
+	env, err := prepareEnvironment(r.config.Environments[opts.Environment])
+	if err != nil {
+		return bisect.Result{}, err
+	}
+
+	good, err := git.Resolve(".", opts.Good)
+	if err != nil {
+		return bisect.Result{}, errors.Wrapf(err, "invalid revision %s", opts.Good)
+	}
+
+	bad, err := git.Resolve(".", opts.Bad)
+	if err != nil {
+		return bisect.Result{}, errors.Wrapf(err, "invalid revision %s", opts.Bad)
+	}
+
+	commits, err := git.Range(".", good, bad)
+	if err != nil {
+		return bisect.Result{}, err
+	}
+
+	fmt.Printf("  \033[36mbisecting \033[m%d commits between %s and %s\n\n", len(commits), opts.Good, opts.Bad)
+
+	runID := newRunID()
+
+	// every commit is benchmarked on its own worktree
+	measure := func(commit string) (reporter.Summary, error) {
+
+		dir, err := git.AddWorktree(".", commit)
+		if err != nil {
+			return reporter.Summary{}, err
+		}
+		defer git.RemoveWorktree(".", dir)
+
+		fmt.Printf("  \033[36mbenchmarking commit \033[m%s\n", commit[:8])
+
+		b, err := newBuilder(env, dir, builders.Labels(runID, opts.Environment, Version), os.Stdout)
+		if err != nil {
+			return reporter.Summary{}, err
+		}
+
+		rp, err := r.BuildRuntime(ctx, b, env, opts.Display, os.Stdout)
+		if err != nil {
+			return reporter.Summary{}, err
+		}
+
+		for _, s := range rp.Summaries {
+			if s.Benchmark == opts.Benchmark && s.Unit == opts.Unit {
+				return s, nil
+			}
+		}
+		return reporter.Summary{}, errors.Errorf("%s (%s) not found on the benchmark results", opts.Benchmark, opts.Unit)
+	}
+
+	return bisect.Run(good, commits, opts.Threshold, measure)
+}
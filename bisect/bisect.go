@@ -0,0 +1,115 @@
+package bisect
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/drish/ben/regression"
+	"github.com/drish/ben/reporter"
+	"github.com/pkg/errors"
+)
+
+// Measure benchmarks a commit returning the bisected benchmark metric
+type Measure func(commit string) (reporter.Summary, error)
+
+// Step is a measured commit
+type Step struct {
+	Commit string
+	Mean   float64
+	Delta  float64 // relative change against the good commit
+	Bad    bool
+}
+
+// Result holds the first bad commit and every measured commit in measurement order
+type Result struct {
+	Good    Step
+	Culprit string
+	Steps   []Step
+}
+
+// Run binary searches `commits`, oldest first and ending on the bad commit, for the
+// first commit slower than `good` beyond `threshold`
+func Run(good string, commits []string, threshold float64, measure Measure) (Result, error) {
+
+	var res Result
+
+	if len(commits) == 0 {
+		return res, errors.New("no commits between good and bad revisions")
+	}
+
+	base, err := measure(good)
+	if err != nil {
+		return res, errors.Wrapf(err, "failed measuring %s", good)
+	}
+	if base.Mean == 0 {
+		return res, errors.Errorf("%s can't be compared, its mean is zero", good)
+	}
+	res.Good = Step{Commit: good, Mean: base.Mean}
+
+	step := func(commit string) (bool, error) {
+		s, err := measure(commit)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed measuring %s", commit)
+		}
+
+		delta, bad := regression.Compare(base.Mean, s.Mean, base.HigherIsBetter, threshold)
+		res.Steps = append(res.Steps, Step{Commit: commit, Mean: s.Mean, Delta: delta, Bad: bad})
+		return bad, nil
+	}
+
+	// the bad revision must be confirmed before searching
+	last := len(commits) - 1
+	bad, err := step(commits[last])
+	if err != nil {
+		return res, err
+	}
+	if !bad {
+		return res, errors.Errorf("%s isn't slower than %s beyond the threshold", commits[last], good)
+	}
+
+	// `lo` is the last known good commit, `hi` the first known bad one
+	lo, hi := -1, last
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+
+		bad, err := step(commits[mid])
+		if err != nil {
+			return res, err
+		}
+
+		if bad {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	res.Culprit = commits[hi]
+	return res, nil
+}
+
+// WriteTable writes every measured commit, the good one first
+func WriteTable(w io.Writer, res Result, unit string) error {
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "commit\tmean\tchange\tstatus")
+	fmt.Fprintf(tw, "%s\t%s %s\t-\tgood\n", short(res.Good.Commit), reporter.FormatValue(res.Good.Mean), unit)
+
+	for _, s := range res.Steps {
+		status := "good"
+		if s.Bad {
+			status = "bad"
+		}
+		fmt.Fprintf(tw, "%s\t%s %s\t%+.2f%%\t%s\n", short(s.Commit), reporter.FormatValue(s.Mean), unit, s.Delta*100, status)
+	}
+
+	return tw.Flush()
+}
+
+func short(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
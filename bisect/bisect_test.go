@@ -0,0 +1,76 @@
+package bisect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drish/ben/reporter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// measures commits from a fixed table of means
+func means(values map[string]float64) Measure {
+	return func(commit string) (reporter.Summary, error) {
+		v, ok := values[commit]
+		if !ok {
+			return reporter.Summary{}, errors.New("benchmark not found")
+		}
+		s := reporter.Summary{Benchmark: "BenchmarkFib10", Unit: "ns/op"}
+		s.Mean = v
+		return s, nil
+	}
+}
+
+func TestRun(t *testing.T) {
+
+	commits := []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7"}
+
+	t.Run("finds the first bad commit", func(t *testing.T) {
+		m := means(map[string]float64{
+			"good": 100, "c1": 101, "c2": 99, "c3": 102, "c4": 130, "c5": 131, "c6": 129, "c7": 132,
+		})
+
+		res, err := Run("good", commits, 0.1, m)
+		assert.Nil(t, err)
+		assert.Equal(t, res.Culprit, "c4")
+		assert.Equal(t, res.Steps[0].Commit, "c7")
+		assert.Equal(t, len(res.Steps), 4)
+
+		var buf bytes.Buffer
+		assert.Nil(t, WriteTable(&buf, res, "ns/op"))
+		assert.Contains(t, buf.String(), "+30.00%")
+	})
+
+	t.Run("bad commit is the last one", func(t *testing.T) {
+		m := means(map[string]float64{
+			"good": 100, "c1": 100, "c2": 100, "c3": 100, "c4": 100, "c5": 100, "c6": 100, "c7": 200,
+		})
+
+		res, err := Run("good", commits, 0.1, m)
+		assert.Nil(t, err)
+		assert.Equal(t, res.Culprit, "c7")
+	})
+
+	t.Run("bad revision isn't slower", func(t *testing.T) {
+		m := means(map[string]float64{"good": 100, "c7": 105})
+
+		_, err := Run("good", commits, 0.1, m)
+		assert.EqualError(t, err, "c7 isn't slower than good beyond the threshold")
+	})
+
+	t.Run("measure failure", func(t *testing.T) {
+		m := means(map[string]float64{"good": 100, "c7": 200})
+
+		res, err := Run("good", commits, 0.1, m)
+		assert.EqualError(t, err, "failed measuring c3: benchmark not found")
+		assert.Equal(t, res.Good.Commit, "good")
+		assert.Equal(t, len(res.Steps), 1)
+		assert.Equal(t, res.Steps[0].Commit, "c7")
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		_, err := Run("good", nil, 0.1, means(nil))
+		assert.EqualError(t, err, "no commits between good and bad revisions")
+	})
+}
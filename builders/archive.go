@@ -0,0 +1,115 @@
+package builders
+
+import (
+	"archive/tar"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// stdout stream of multiplexed container logs
+const streamStdout = 1
+
+// tarDir streams a tar archive of the contents of `dir`, the working directory
+// when blank, for copying into containers without the docker cli
+func tarDir(dir string) io.ReadCloser {
+	if dir == "" {
+		dir = "."
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(writeTar(w, dir))
+	}()
+	return r
+}
+
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if info.IsDir() && excluded(rel) {
+			return filepath.SkipDir
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		// sockets, devices and pipes aren't copied
+		if !info.IsDir() && !info.Mode().IsRegular() && link == "" {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed archiving project")
+	}
+
+	return tw.Close()
+}
+
+// demuxLogs writes the stdout frames of multiplexed container logs to `stdout`,
+// logs of containers without a tty start every frame with an 8 bytes header
+// holding the stream and the frame size
+func demuxLogs(r io.Reader, stdout io.Writer) error {
+	header := make([]byte, 8)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "failed reading logs")
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+
+		dst := ioutil.Discard
+		if header[0] == streamStdout {
+			dst = stdout
+		}
+		if _, err := io.CopyN(dst, r, size); err != nil {
+			return errors.Wrap(err, "failed reading logs")
+		}
+	}
+}
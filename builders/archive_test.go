@@ -0,0 +1,83 @@
+package builders
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive_tarDir(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ben-project-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "pkg", "bench.sh"), []byte("#!/bin/sh"), 0755))
+	assert.Nil(t, os.Symlink("main.go", filepath.Join(dir, "link.go")))
+
+	// the run history isn't copied
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, ".ben", "history"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, ".ben", "history", "run.json"), []byte("{}"), 0644))
+
+	archive := tarDir(dir)
+	defer archive.Close()
+
+	var names []string
+	contents := map[string]string{}
+
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+
+		names = append(names, hdr.Name)
+		if hdr.Typeflag == tar.TypeSymlink {
+			contents[hdr.Name] = "-> " + hdr.Linkname
+			continue
+		}
+		b, _ := ioutil.ReadAll(tr)
+		contents[hdr.Name] = string(b)
+	}
+
+	sort.Strings(names)
+	assert.Equal(t, names, []string{"link.go", "main.go", "pkg/", "pkg/bench.sh"})
+	assert.Equal(t, contents["main.go"], "package main")
+	assert.Equal(t, contents["link.go"], "-> main.go")
+}
+
+func TestArchive_demuxLogs(t *testing.T) {
+
+	frame := func(stream byte, s string) []byte {
+		header := make([]byte, 8)
+		header[0] = stream
+		binary.BigEndian.PutUint32(header[4:], uint32(len(s)))
+		return append(header, s...)
+	}
+
+	var logs []byte
+	logs = append(logs, frame(1, "BenchmarkFib10  3000000  413 ns/op\n")...)
+	logs = append(logs, frame(2, "warning: slow\n")...)
+	logs = append(logs, frame(1, "PASS\n")...)
+
+	var out bytes.Buffer
+	err := demuxLogs(bytes.NewReader(logs), &out)
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), "BenchmarkFib10  3000000  413 ns/op\nPASS\n")
+
+	// truncated frames are errors
+	err = demuxLogs(bytes.NewReader(logs[:len(logs)-2]), &out)
+	assert.NotNil(t, err)
+}
@@ -1,14 +1,41 @@
 package builders
 
-import "github.com/drish/ben/reporter"
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
 
-// RuntimeBuilder is the interface that defines how to build runtime environments
+	"github.com/drish/ben/reporter"
+)
+
+// RuntimeBuilder is the interface that defines how to build runtime environments,
+// canceling the context stops the running step. Cleanup must remove whatever
+// was created so far, so it's called with a fresh context even after failures.
 type RuntimeBuilder interface {
-	Init() error
-	PrepareImage() error
-	SetupContainer() error
-	Cleanup() error
-	Benchmark() error
+	Init(ctx context.Context) error
+	PrepareImage(ctx context.Context) error
+	SetupContainer(ctx context.Context) error
+	RemoveContainer(ctx context.Context) error
+	Cleanup(ctx context.Context) error
+	Benchmark(ctx context.Context) error
 	Report() reporter.ReportData
 	Display() error
 }
+
+// returns the progress output, stdout when nil
+func output(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// ben's own directory on the project, holding the run history, it grows
+// with every run so it's left out when copying the project
+const stateDir = ".ben"
+
+// reports whether the path, relative to the project root, is left out of project copies
+func excluded(rel string) bool {
+	return filepath.ToSlash(rel) == stateDir
+}
@@ -0,0 +1,13 @@
+package builders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_excluded(t *testing.T) {
+	assert.Equal(t, excluded(".ben"), true)
+	assert.Equal(t, excluded("pkg/.ben"), false)
+	assert.Equal(t, excluded(".benchmarks"), false)
+}
@@ -0,0 +1,179 @@
+package builders
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	dockerTypes "github.com/docker/docker/api/types"
+	dockerFilters "github.com/docker/docker/api/types/filters"
+	docker "github.com/docker/docker/client"
+	hyper "github.com/hyperhq/hyper-api/client"
+	hyperTypes "github.com/hyperhq/hyper-api/types"
+	hyperFilters "github.com/hyperhq/hyper-api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// Leftover is a container or image created by ben and still around
+type Leftover struct {
+	Backend     string // local, podman or hyper
+	Kind        string // container or image
+	ID          string
+	Name        string
+	RunID       string
+	Environment string
+	Version     string
+	Created     time.Time
+}
+
+// Sweeper finds and removes the leftovers of a backend
+type Sweeper interface {
+	// Find lists labelled containers first, then images, so they can be removed in order
+	Find(ctx context.Context) ([]Leftover, error)
+	Remove(ctx context.Context, l Leftover) error
+}
+
+// LocalSweeper sweeps the local docker daemon, or another docker compatible one
+type LocalSweeper struct {
+	Client  *docker.Client
+	Backend string // reported backend, ie: local, podman
+}
+
+// NewLocalSweeper connects to the local docker daemon
+func NewLocalSweeper() (*LocalSweeper, error) {
+	c, err := docker.NewEnvClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to local docker")
+	}
+	return &LocalSweeper{Client: c, Backend: "local"}, nil
+}
+
+// Find lists the containers and images labelled by ben
+func (s *LocalSweeper) Find(ctx context.Context) ([]Leftover, error) {
+
+	args := dockerFilters.NewArgs()
+	args.Add("label", LabelRunID)
+
+	containers, err := s.Client.ContainerList(ctx, dockerTypes.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing local containers")
+	}
+
+	images, err := s.Client.ImageList(ctx, dockerTypes.ImageListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing local images")
+	}
+
+	var found []Leftover
+	for _, c := range containers {
+		found = append(found, leftover(s.Backend, "container", c.ID, containerName(c.Names), c.Created, c.Labels))
+	}
+	for _, i := range images {
+		found = append(found, leftover(s.Backend, "image", i.ID, imageName(i.RepoTags), i.Created, i.Labels))
+	}
+	return found, nil
+}
+
+// Remove force removes the container or image
+func (s *LocalSweeper) Remove(ctx context.Context, l Leftover) error {
+
+	if l.Kind == "container" {
+		err := s.Client.ContainerRemove(ctx, l.ID, dockerTypes.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		return errors.Wrapf(err, "failed removing container %s", l.Name)
+	}
+
+	_, err := s.Client.ImageRemove(ctx, l.ID, dockerTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
+	return errors.Wrapf(err, "failed removing image %s", l.Name)
+}
+
+// HyperSweeper sweeps hyper.sh, using the credentials set on the environment
+type HyperSweeper struct {
+	Client *hyper.Client
+}
+
+// NewHyperSweeper connects to hyper.sh
+func NewHyperSweeper() (*HyperSweeper, error) {
+	c, _, err := newHyperClient()
+	if err != nil {
+		return nil, err
+	}
+	return &HyperSweeper{Client: c}, nil
+}
+
+// Find lists the containers and images labelled by ben
+func (s *HyperSweeper) Find(ctx context.Context) ([]Leftover, error) {
+
+	args := hyperFilters.NewArgs()
+	args.Add("label", LabelRunID)
+
+	containers, err := s.Client.ContainerList(ctx, hyperTypes.ContainerListOptions{All: true, Filter: args})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing hyper.sh containers")
+	}
+
+	images, err := s.Client.ImageList(ctx, hyperTypes.ImageListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing hyper.sh images")
+	}
+
+	var found []Leftover
+	for _, c := range containers {
+		found = append(found, leftover("hyper", "container", c.ID, containerName(c.Names), c.Created, c.Labels))
+	}
+	for _, i := range images {
+		found = append(found, leftover("hyper", "image", i.ID, imageName(i.RepoTags), i.Created, i.Labels))
+	}
+	return found, nil
+}
+
+// Remove force removes the container or image
+func (s *HyperSweeper) Remove(ctx context.Context, l Leftover) error {
+
+	if l.Kind == "container" {
+		_, err := s.Client.ContainerRemove(ctx, l.ID, hyperTypes.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		return errors.Wrapf(err, "failed removing container %s", l.Name)
+	}
+
+	_, err := s.Client.ImageRemove(ctx, l.ID, hyperTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
+	return errors.Wrapf(err, "failed removing image %s", l.Name)
+}
+
+// OlderThan keeps the leftovers created more than `age` before `now`
+func OlderThan(leftovers []Leftover, age time.Duration, now time.Time) []Leftover {
+	var old []Leftover
+	for _, l := range leftovers {
+		if now.Sub(l.Created) > age {
+			old = append(old, l)
+		}
+	}
+	return old
+}
+
+func leftover(backend, kind, id, name string, created int64, labels map[string]string) Leftover {
+	return Leftover{
+		Backend:     backend,
+		Kind:        kind,
+		ID:          id,
+		Name:        name,
+		RunID:       labels[LabelRunID],
+		Environment: labels[LabelEnvironment],
+		Version:     labels[LabelVersion],
+		Created:     time.Unix(created, 0),
+	}
+}
+
+// container names are listed with a leading slash
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
+
+// untagged images are named by their id
+func imageName(tags []string) string {
+	if len(tags) == 0 || tags[0] == "<none>:<none>" {
+		return ""
+	}
+	return tags[0]
+}
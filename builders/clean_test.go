@@ -0,0 +1,51 @@
+package builders
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClean_leftover(t *testing.T) {
+	l := leftover("local", "container", "abc", containerName([]string{"/ben-tmp-x"}), 1500000000, Labels("run1", 2, "0.2.0"))
+
+	assert.Equal(t, l.Name, "ben-tmp-x")
+	assert.Equal(t, l.RunID, "run1")
+	assert.Equal(t, l.Environment, "2")
+	assert.Equal(t, l.Version, "0.2.0")
+	assert.Equal(t, l.Created, time.Unix(1500000000, 0))
+	assert.Equal(t, imageName([]string{"<none>:<none>"}), "")
+}
+
+func TestClean_OlderThan(t *testing.T) {
+	now := time.Now()
+	leftovers := []Leftover{
+		{ID: "old", Created: now.Add(-48 * time.Hour)},
+		{ID: "new", Created: now.Add(-time.Hour)},
+	}
+
+	assert.Equal(t, len(OlderThan(leftovers, 0, now)), 2)
+
+	old := OlderThan(leftovers, 24*time.Hour, now)
+	assert.Equal(t, len(old), 1)
+	assert.Equal(t, old[0].ID, "old")
+}
+
+func TestLabels_withLabels(t *testing.T) {
+	labels := Labels("run1", 0, "0.2.0")
+	merged := withLabels(labels, map[string]string{"sh_hyper_instancetype": "s4"})
+
+	assert.Equal(t, len(merged), 4)
+	assert.Equal(t, len(labels), 3)
+}
+
+func TestLabels_resourceName(t *testing.T) {
+	name := resourceName("ben-final", Labels("run1", 2, "0.2.0"))
+	assert.Equal(t, strings.HasPrefix(name, "ben-final-run1-2-"), true)
+	assert.Equal(t, len(name), len("ben-final-run1-2-")+8)
+	assert.NotEqual(t, resourceName("ben-final", Labels("run1", 2, "0.2.0")), name)
+
+	assert.Equal(t, len(resourceName("ben-tmp", nil)), len("ben-tmp-")+8)
+}
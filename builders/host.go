@@ -0,0 +1,330 @@
+package builders
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/drish/ben/reporter"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	Register(Backend{
+		Prefix: "host",
+		Validate: func(size string) error {
+			if size != "" {
+				return errors.Errorf("invalid host size: %s", size)
+			}
+			return nil
+		},
+		Local: true,
+		Check: func(spec Spec) error {
+			if spec.Version != "" {
+				return errors.New("host machines run the installed toolchain, version can't be set")
+			}
+			return nil
+		},
+		New: func(opts Options) (RuntimeBuilder, error) {
+			return &HostBuilder{
+				Runtime: opts.Runtime,
+				Before:  opts.Before,
+				Command: opts.Command,
+				Env:     opts.Env,
+				Source:  opts.Source,
+				Output:  opts.Output,
+			}, nil
+		},
+	})
+}
+
+// commands printing the toolchain version of each runtime
+var toolchainCommands = map[string][]string{
+	"golang": {"go", "version"},
+	"ruby":   {"ruby", "--version"},
+	"jruby":  {"jruby", "--version"},
+	"node":   {"node", "--version"},
+	"python": {"python", "--version"},
+	"pypy":   {"pypy", "--version"},
+}
+
+// HostBuilder runs the benchmark as processes on the host, without docker,
+// the runtime version is whatever toolchain is installed on the host
+type HostBuilder struct {
+	Runtime string            // runtime name, picks the toolchain reported, ie: golang
+	Command []string          // benchmark command
+	Before  []string          // commands to run before bench
+	Env     []string          // environment variables added to the clean environment, ie: GOMAXPROCS=1
+	Source  string            // project directory copied into the scratch directory, default to the working directory
+	Dir     string            // scratch directory the commands run in
+	Results string            // benchmark output
+	Info    reporter.HostInfo // reported instead of the docker info
+	Output  io.Writer         // progress output, default to stdout
+}
+
+// Init collects the host info
+func (h *HostBuilder) Init(ctx context.Context) error {
+
+	fmt.Fprintf(h.out(), "  \033[36msetting up host environment for \033[m%s \n", h.Runtime)
+
+	h.Info = reporter.HostInfo{
+		OS:        hostOS(ctx),
+		Kernel:    commandOutput(ctx, "uname", "-sr"),
+		CPU:       hostCPU(ctx),
+		Arch:      runtime.GOARCH,
+		Toolchain: h.toolchain(ctx),
+	}
+	return nil
+}
+
+// PrepareImage copies the project into a scratch directory and runs `before` commands on it
+func (h *HostBuilder) PrepareImage(ctx context.Context) error {
+
+	dir, err := ioutil.TempDir("", "ben-host-")
+	if err != nil {
+		return errors.Wrap(err, "failed creating scratch directory")
+	}
+	h.Dir = dir
+
+	src := h.Source
+	if src == "" {
+		src = "."
+	}
+
+	if err := copyDir(src, dir); err != nil {
+		fmt.Fprintf(h.out(), "\r  \033[36mcopying project \033[m %s\n", color.RedString("failed !"))
+		return errors.Wrap(err, "failed copying project")
+	}
+	fmt.Fprintf(h.out(), "\r  \033[36mcopying project \033[m %s\n", color.GreenString("done !"))
+
+	if len(h.Before) == 0 {
+		fmt.Fprintf(h.out(), " \033[36m no commands to run before !\n\033[m")
+		return nil
+	}
+
+	out, err := h.command(ctx, h.Before).CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(h.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(h.Before, " "))
+		fmt.Fprintln(h.out())
+		fmt.Fprint(h.out(), string(out))
+		return errors.New("running 'before' commands failed")
+	}
+
+	fmt.Fprintf(h.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(h.Before, " "))
+	return nil
+}
+
+// SetupContainer checks the benchmark is ready to run, there's no container on the host
+func (h *HostBuilder) SetupContainer(ctx context.Context) error {
+
+	if h.Command == nil {
+		return errors.New("command can not be blank")
+	}
+
+	if h.Dir == "" {
+		return errors.New("scratch directory not prepared")
+	}
+	return nil
+}
+
+// Benchmark runs the benchmark command on the scratch directory, storing its stdout
+func (h *HostBuilder) Benchmark(ctx context.Context) error {
+
+	fmt.Fprintf(h.out(), "\r  \033[36mrunning benchmark \033[m (%s)", strings.Join(h.Command, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd := h.command(ctx, h.Command)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+	h.Results = stdout.String()
+	if err != nil {
+		fmt.Fprintf(h.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(h.Command, " "))
+		fmt.Fprint(h.out(), stderr.String())
+		return errors.Wrap(err, "benchmark command failed")
+	}
+
+	fmt.Fprintf(h.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(h.Command, " "))
+	return nil
+}
+
+// RemoveContainer does nothing, the scratch directory is kept for further runs
+func (h *HostBuilder) RemoveContainer(ctx context.Context) error {
+	return nil
+}
+
+// Cleanup removes the scratch directory
+func (h *HostBuilder) Cleanup(ctx context.Context) error {
+
+	if h.Dir == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(h.Dir); err != nil {
+		return errors.Wrap(err, "failed removing scratch directory")
+	}
+	h.Dir = ""
+
+	fmt.Fprintln(h.out())
+	fmt.Fprintf(h.out(), "  \033[36mcleaning up scratch directory\033[m %s \n", color.GreenString(" done !"))
+	return nil
+}
+
+// Display writes the benchmark output to stdout
+func (h *HostBuilder) Display() error {
+	fmt.Fprintf(h.out(), "  \033[36mdisplaying results\033[m \n")
+	fmt.Fprintln(h.out(), h.Results)
+	return nil
+}
+
+// Report returns data for being later written to fs
+func (h *HostBuilder) Report() reporter.ReportData {
+	info := h.Info
+	return reporter.ReportData{
+		Image:   h.Runtime,
+		Results: h.Results,
+		Machine: "host",
+		Before:  strings.Join(h.Before, " "),
+		Command: strings.Join(h.Command, " "),
+		Host:    &info,
+	}
+}
+
+// runs `args` on the scratch directory with a clean environment, only
+// keeping the host PATH and HOME so toolchains can be found
+func (h *HostBuilder) command(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = h.Dir
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH"), "HOME=" + os.Getenv("HOME")}, h.Env...)
+	return cmd
+}
+
+// toolchain version of the runtime, blank when unknown or not installed
+func (h *HostBuilder) toolchain(ctx context.Context) string {
+	args, ok := toolchainCommands[h.Runtime]
+	if !ok {
+		return ""
+	}
+	out, err := h.command(ctx, args).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (h *HostBuilder) out() io.Writer {
+	return output(h.Output)
+}
+
+// operating system name, ie: Ubuntu 16.04.3 LTS
+func hostOS(ctx context.Context) string {
+	if runtime.GOOS == "darwin" {
+		if v := commandOutput(ctx, "sw_vers", "-productVersion"); v != "" {
+			return "macOS " + v
+		}
+	}
+
+	if f, err := os.Open("/etc/os-release"); err == nil {
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			if strings.HasPrefix(sc.Text(), "PRETTY_NAME=") {
+				return strings.Trim(strings.TrimPrefix(sc.Text(), "PRETTY_NAME="), `"`)
+			}
+		}
+	}
+	return runtime.GOOS
+}
+
+// cpu model name, ie: Intel(R) Core(TM) i7-7700HQ CPU @ 2.80GHz
+func hostCPU(ctx context.Context) string {
+	if runtime.GOOS == "darwin" {
+		return commandOutput(ctx, "sysctl", "-n", "machdep.cpu.brand_string")
+	}
+
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.SplitN(sc.Text(), ":", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "model name" {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
+
+// trimmed output of the command, blank when it fails
+func commandOutput(ctx context.Context, name string, args ...string) string {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// copies the contents of `src` into `dst`, keeping file modes and symlinks
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && excluded(rel) {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			// sockets, devices and pipes aren't copied
+			return nil
+		}
+
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
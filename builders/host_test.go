@@ -0,0 +1,96 @@
+package builders
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_HostBuilder(t *testing.T) {
+
+	ctx := context.Background()
+
+	project, err := ioutil.TempDir("", "ben-project-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(project)
+
+	err = ioutil.WriteFile(filepath.Join(project, "input.txt"), []byte("project"), 0644)
+	assert.Nil(t, err)
+
+	t.Run("runs on a copy of the project", func(t *testing.T) {
+		builder := &HostBuilder{
+			Runtime: "golang",
+			Source:  project,
+			Before:  []string{"bash", "-c", "cat input.txt > before.txt"},
+			Command: []string{"cat", "before.txt"},
+			Output:  ioutil.Discard,
+		}
+
+		assert.Nil(t, builder.Init(ctx))
+		assert.Nil(t, builder.PrepareImage(ctx))
+		assert.Nil(t, builder.SetupContainer(ctx))
+		assert.Nil(t, builder.Benchmark(ctx))
+		assert.Equal(t, builder.Results, "project")
+
+		// the project itself is untouched
+		_, err := os.Stat(filepath.Join(project, "before.txt"))
+		assert.Equal(t, os.IsNotExist(err), true)
+
+		dir := builder.Dir
+		assert.Nil(t, builder.Cleanup(ctx))
+		_, err = os.Stat(dir)
+		assert.Equal(t, os.IsNotExist(err), true)
+
+		rp := builder.Report()
+		assert.Equal(t, rp.Machine, "host")
+		assert.NotNil(t, rp.Host)
+	})
+
+	t.Run("clean environment", func(t *testing.T) {
+		os.Setenv("BEN_TEST_HOST", "leaked")
+		defer os.Unsetenv("BEN_TEST_HOST")
+
+		builder := &HostBuilder{
+			Source:  project,
+			Env:     []string{"GOMAXPROCS=1"},
+			Command: []string{"sh", "-c", "echo $GOMAXPROCS-$BEN_TEST_HOST"},
+			Output:  ioutil.Discard,
+		}
+		defer builder.Cleanup(ctx)
+
+		assert.Nil(t, builder.PrepareImage(ctx))
+		assert.Nil(t, builder.Benchmark(ctx))
+		assert.Equal(t, builder.Results, "1-\n")
+	})
+
+	t.Run("failed benchmark keeps the output", func(t *testing.T) {
+		var out bytes.Buffer
+		builder := &HostBuilder{
+			Source:  project,
+			Command: []string{"sh", "-c", "echo broken; echo oops >&2; exit 1"},
+			Output:  &out,
+		}
+		defer builder.Cleanup(ctx)
+
+		assert.Nil(t, builder.PrepareImage(ctx))
+		err := builder.Benchmark(ctx)
+		assert.NotNil(t, err)
+		assert.Equal(t, builder.Results, "broken\n")
+		assert.Contains(t, out.String(), "oops\n")
+	})
+
+	t.Run("version", func(t *testing.T) {
+		assert.Nil(t, Check(Spec{Machine: "host"}))
+		assert.NotNil(t, Check(Spec{Machine: "host", Version: "1.9"}))
+	})
+
+	t.Run("nothing to cleanup", func(t *testing.T) {
+		builder := &HostBuilder{}
+		assert.Nil(t, builder.Cleanup(ctx))
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -35,20 +36,49 @@ var (
 	verStr = "v1.23"
 )
 
-var (
-	sizesDescription = map[string]string{
-		"s1": "s1 - 1 CPU 64MB",
-		"s2": "s2 - 1 CPU 128MB",
-		"s3": "s3 - 1 CPU 256MB",
-		"s4": "s4 - 1 CPU 512MB",
-		"m1": "m1 - 1 CPU 1GB",
-		"m2": "m2 - 2 CPU 2GB",
-		"m3": "m3 - 2 CPU 4GB",
-		"l1": "l1 - 4 CPU 4GB",
-		"l2": "l2 - 4 CPU 8GB",
-		"l3": "l3 - 8 CPU 16GB",
-	}
-)
+// hyper.sh machine sizes, also emulated on local docker by local-as-hyper-<size> machines
+var hyperSizes = map[string]Limits{
+	"s1": {CPUs: 1, Memory: 64 << 20},
+	"s2": {CPUs: 1, Memory: 128 << 20},
+	"s3": {CPUs: 1, Memory: 256 << 20},
+	"s4": {CPUs: 1, Memory: 512 << 20},
+	"m1": {CPUs: 1, Memory: 1 << 30},
+	"m2": {CPUs: 2, Memory: 2 << 30},
+	"m3": {CPUs: 2, Memory: 4 << 30},
+	"l1": {CPUs: 4, Memory: 4 << 30},
+	"l2": {CPUs: 4, Memory: 8 << 30},
+	"l3": {CPUs: 8, Memory: 16 << 30},
+}
+
+func init() {
+	var sizes []string
+	for s := range hyperSizes {
+		sizes = append(sizes, s)
+	}
+	sort.Strings(sizes)
+
+	Register(Backend{
+		Prefix: "hyper",
+		Sizes:  sizes,
+		New: func(opts Options) (RuntimeBuilder, error) {
+			return &HyperBuilder{
+				Image:     opts.Image,
+				Before:    opts.Before,
+				HyperSize: opts.Size,
+				Command:   opts.Command,
+				Env:       opts.Env,
+				Source:    opts.Source,
+				Output:    opts.Output,
+				Labels:    opts.Labels,
+			}, nil
+		},
+	})
+}
+
+// describes a hyper size, ie: s4 - 1 CPU 512MB
+func sizeDescription(size string) string {
+	return size + " - " + hyperSizes[size].String()
+}
 
 // HyperBuilder is the Hyper.sh struct for dealing with hyper runtimes
 type HyperBuilder struct {
@@ -57,23 +87,52 @@ type HyperBuilder struct {
 	HyperSize      string
 	Before         []string
 	Command        []string
-	Context        context.Context
+	Env            []string  // environment variables of the before and benchmark containers, ie: GOMAXPROCS=1
+	Source         string    // project directory copied into the image, default to the working directory
+	Output         io.Writer // progress output, default to stdout
 	HyperClient    *hyper.Client
 	HyperRegion    string
 	DockerClient   *docker.Client
 	BenchmarkImage string
 	Results        string
+	ExitCode       int               // exit code of the benchmark command
+	Labels         map[string]string // set on every container, committed images inherit them
+
+	tmp      string // temporary local container, removed on cleanup if left behind
+	local    bool   // the benchmark image is on the local docker
+	uploaded bool   // the benchmark image is on hyper
 }
 
 // Init does requirements checks and sets up necessary variables
-func (b *HyperBuilder) Init() error {
+func (b *HyperBuilder) Init(ctx context.Context) error {
+
+	hyperClient, region, err := newHyperClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(b.out(), "\r  \033[36msetting up environment on Hyper.sh %s for \033[m%s \n", region, b.Image)
+
+	dockerClient, err := docker.NewEnvClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to local docker")
+	}
+
+	b.DockerClient = dockerClient
+	b.HyperClient = hyperClient
+	b.HyperRegion = region
+	return nil
+}
+
+// connects to hyper.sh with the credentials and region set on the environment
+func newHyperClient() (*hyper.Client, string, error) {
 
 	accessKey := os.Getenv("HYPER_ACCESSKEY")
 	secretKey := os.Getenv("HYPER_SECRETKEY")
 	region := os.Getenv("HYPER_REGION")
 
 	if accessKey == "" || secretKey == "" {
-		return errors.New("missing hyper.sh credentials")
+		return nil, "", errors.New("missing hyper.sh credentials")
 	}
 
 	// set default
@@ -84,58 +143,47 @@ func (b *HyperBuilder) Init() error {
 	host := hosts[region]
 
 	if host == "" {
-		return errors.New("invalid region set")
+		return nil, "", errors.New("invalid region set")
 	}
 
-	fmt.Printf("\r  \033[36msetting up environment on Hyper.sh %s for \033[m%s \n", region, b.Image)
-
 	httpClient := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		},
 	}
 
-	hyperClient, err := hyper.NewClient(host, verStr, httpClient, map[string]string{}, accessKey, secretKey, region)
+	c, err := hyper.NewClient(host, verStr, httpClient, map[string]string{}, accessKey, secretKey, region)
 	if err != nil {
-		return errors.Wrap(err, "failed to setup hyper.sh client")
-	}
-
-	dockerClient, err := docker.NewEnvClient()
-	if err != nil {
-		return errors.Wrap(err, "failed to connect to local docker")
+		return nil, "", errors.Wrap(err, "failed to setup hyper.sh client")
 	}
 
-	b.DockerClient = dockerClient
-	b.HyperClient = hyperClient
-	b.HyperRegion = region
-	b.Context = context.Background()
-	return nil
+	return c, region, nil
 }
 
 // PrepareImage pulls the base image and run `before` commands
-func (b *HyperBuilder) PrepareImage() error {
+func (b *HyperBuilder) PrepareImage(ctx context.Context) error {
 
-	if err := b.pullImage(); err != nil {
+	if err := b.pullImage(ctx); err != nil {
 		return err
 	}
 
-	if err := b.setupBaseImage(); err != nil {
+	if err := b.setupBaseImage(ctx); err != nil {
 		return err
 	}
 
-	if err := b.runBeforeCommands(); err != nil {
+	if err := b.runBeforeCommands(ctx); err != nil {
 		return err
 	}
 
-	if err := b.loadOnHyper(); err != nil {
+	if err := b.loadOnHyper(ctx); err != nil {
 		return err
 	}
 
-	if err := b.waitForImage(); err != nil {
+	if err := b.waitForImage(ctx); err != nil {
 		return err
 	}
 
-	if err := b.removeLocalImage(); err != nil {
+	if err := b.removeLocalImage(ctx); err != nil {
 		return err
 	}
 
@@ -143,15 +191,13 @@ func (b *HyperBuilder) PrepareImage() error {
 }
 
 // SetupContainer creates the container on hyper
-func (b *HyperBuilder) SetupContainer() error {
+func (b *HyperBuilder) SetupContainer(ctx context.Context) error {
 
 	if b.Command == nil {
-		b.Cleanup()
 		return errors.New("command can not be blank")
 	}
 
 	if b.BenchmarkImage == "" {
-		b.Cleanup()
 		return errors.New("benchmark image not prepared")
 	}
 
@@ -160,26 +206,26 @@ func (b *HyperBuilder) SetupContainer() error {
 		WorkingDir: "/tmp",
 		OpenStdin:  true,
 		Cmd:        b.Command,
-		Labels: map[string]string{
+		Env:        b.Env,
+		Labels: withLabels(b.Labels, map[string]string{
 			"sh_hyper_instancetype": b.HyperSize,
-		},
+		}),
 	}
 
-	c, err := b.HyperClient.ContainerCreate(b.Context, config, nil, nil, "")
+	c, err := b.HyperClient.ContainerCreate(ctx, config, nil, nil, "")
 	if err != nil {
-		b.Cleanup()
-		fmt.Printf("\r  \033[36mcreating benchmark container \033[m %s ", color.RedString("failed !"))
+		fmt.Fprintf(b.out(), "\r  \033[36mcreating benchmark container \033[m %s ", color.RedString("failed !"))
 		return errors.Wrap(err, "failed creating benchmark container")
 	}
 
-	fmt.Printf("  \033[36mcreating benchmark container \033[m %s (%s) \n", color.GreenString("done !"), sizesDescription[b.HyperSize])
+	fmt.Fprintf(b.out(), "  \033[36mcreating benchmark container \033[m %s (%s) \n", color.GreenString("done !"), sizeDescription(b.HyperSize))
 
 	b.ID = c.ID
 	return nil
 }
 
 // Benchmark runs the benchmark command on hyper
-func (b *HyperBuilder) Benchmark() error {
+func (b *HyperBuilder) Benchmark(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -189,27 +235,35 @@ func (b *HyperBuilder) Benchmark() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(200 * time.Millisecond)
-			fmt.Printf("\r  \033[36mrunning benchmark \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(b.Command, " "))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(200 * time.Millisecond):
+			}
+			fmt.Fprintf(b.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(b.Command, " "))
 		}
-		fmt.Printf("\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(b.Command, " "))
+	}()
 
+	// stops the spinner on every return
+	defer func() {
+		spin = false
+		wg.Wait()
 	}()
 
 	// start container
-	err := b.HyperClient.ContainerStart(b.Context, b.ID, "")
+	err := b.HyperClient.ContainerStart(ctx, b.ID, "")
 	if err != nil {
 		return errors.Wrap(err, "couldn't start container")
 	}
 
 	// wait until container exits
-	_, errC := b.HyperClient.ContainerWait(b.Context, b.ID)
-	if err := errC; err != nil {
+	status, err := b.HyperClient.ContainerWait(ctx, b.ID)
+	if err != nil {
 		return errors.Wrap(err, "failed to wait for container status")
 	}
 
 	// store container logs
-	reader, err := b.HyperClient.ContainerLogs(b.Context, b.ID, hyperTypes.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	reader, err := b.HyperClient.ContainerLogs(ctx, b.ID, hyperTypes.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch logs")
 	}
@@ -220,16 +274,44 @@ func (b *HyperBuilder) Benchmark() error {
 	}
 
 	b.Results = utils.StripCtlAndExtFromUnicode(string(results))
+	b.ExitCode = status
 	spin = false
 
 	wg.Wait()
+	if b.ExitCode != 0 {
+		fmt.Fprintf(b.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(b.Command, " "))
+		return errors.Errorf("benchmark command exited with %d", b.ExitCode)
+	}
+	fmt.Fprintf(b.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(b.Command, " "))
+	return nil
+}
+
+// RemoveContainer removes the benchmark container on hyper, keeping the benchmark image for further runs
+func (b *HyperBuilder) RemoveContainer(ctx context.Context) error {
+
+	if b.ID == "" {
+		return errors.New("container doesn't exist")
+	}
+
+	_, err := b.HyperClient.ContainerRemove(ctx, b.ID, hyperTypes.ContainerRemoveOptions{RemoveVolumes: true})
+	if err != nil {
+		return errors.Wrap(err, "failed removing container")
+	}
+
+	b.ID = ""
 	return nil
 }
 
-// Cleanup cleans up containers on hyper
-func (b *HyperBuilder) Cleanup() error {
+// Cleanup removes the containers and images used for benchmarking, on hyper and on
+// the local docker, it's safe to call at any point and only removes what was created
+func (b *HyperBuilder) Cleanup(ctx context.Context) error {
 
-	fmt.Println()
+	// nothing was created before connecting to hyper
+	if b.HyperClient == nil || b.DockerClient == nil {
+		return nil
+	}
+
+	fmt.Fprintln(b.out())
 	var wg sync.WaitGroup
 	wg.Add(1)
 
@@ -238,38 +320,65 @@ func (b *HyperBuilder) Cleanup() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36mcleaning up container and volumes\033[m %s", color.MagentaString(s.Next()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(b.out(), "\r  \033[36mcleaning up container and volumes\033[m %s", color.MagentaString(s.Next()))
 		}
-		fmt.Printf("\r  \033[36mcleaning up container and volumes \033[m %s\n", color.GreenString("done !"))
-
 	}()
 
-	if b.ID == "" {
-		return errors.New("container doesn't exist")
+	var failed error
+	check := func(err error, msg string) {
+		if err != nil && failed == nil {
+			failed = errors.Wrap(err, msg)
+		}
 	}
 
-	// try to remove benchmark container
-	_, err := b.HyperClient.ContainerRemove(b.Context, b.ID, hyperTypes.ContainerRemoveOptions{RemoveVolumes: true})
-	if err != nil {
-		return errors.Wrap(err, "failed removing container")
+	// containers still running after an interrupt are killed
+	if b.ID != "" {
+		_, err := b.HyperClient.ContainerRemove(ctx, b.ID, hyperTypes.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		check(err, "failed removing container")
+		b.ID = ""
 	}
 
-	// delete the image
-	_, err = b.HyperClient.ImageRemove(b.Context, b.BenchmarkImage, hyperTypes.ImageRemoveOptions{})
-	if err != nil {
-		return errors.Wrap(err, "failed removing benchmark image")
+	if b.uploaded {
+		_, err := b.HyperClient.ImageRemove(ctx, b.BenchmarkImage, hyperTypes.ImageRemoveOptions{})
+		check(err, "failed removing benchmark image")
+		b.uploaded = false
+	}
+
+	// leftovers of a failed image preparation
+	if b.tmp != "" {
+		err := b.DockerClient.ContainerRemove(ctx, b.tmp, dockerTypes.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		check(err, "failed removing container")
+		b.tmp = ""
+	}
+
+	if b.local {
+		_, err := b.DockerClient.ImageRemove(ctx, b.BenchmarkImage, dockerTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
+		check(err, "failed removing benchmark image")
+		os.Remove(b.BenchmarkImage + ".tar")
+		b.local = false
 	}
 
 	spin = false
 	wg.Wait()
+
+	if failed != nil {
+		fmt.Fprintf(b.out(), "\r  \033[36mcleaning up container and volumes \033[m %s\n", color.RedString("failed !"))
+		return failed
+	}
+
+	fmt.Fprintf(b.out(), "\r  \033[36mcleaning up container and volumes \033[m %s\n", color.GreenString("done !"))
 	return nil
 }
 
 // Display writes the benchmark output to stdout
 func (b *HyperBuilder) Display() error {
-	fmt.Printf("  \033[36mdisplaying results\033[m \n")
-	fmt.Println(b.Results)
+	fmt.Fprintf(b.out(), "  \033[36mdisplaying results\033[m \n")
+	fmt.Fprintln(b.out(), b.Results)
 	return nil
 }
 
@@ -278,7 +387,7 @@ func (b *HyperBuilder) Report() reporter.ReportData {
 	return reporter.ReportData{
 		Image:   b.Image,
 		Results: b.Results,
-		Machine: "Hyper.sh cloud: " + sizesDescription[b.HyperSize],
+		Machine: "Hyper.sh cloud: " + sizeDescription(b.HyperSize),
 		Before:  strings.Join(b.Before, " "),
 		Command: strings.Join(b.Command, " "),
 	}
@@ -286,7 +395,7 @@ func (b *HyperBuilder) Report() reporter.ReportData {
 
 // NOTE: ugly workaround, hyper takes a while to make the newly created image available.
 // should be replaced by a checker to see if the image was uploaded every X secs
-func (h *HyperBuilder) waitForImage() error {
+func (h *HyperBuilder) waitForImage(ctx context.Context) error {
 	var wg sync.WaitGroup
 	wg.Add(1)
 
@@ -295,25 +404,35 @@ func (h *HyperBuilder) waitForImage() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36mwaiting for image to become available \033[m %s", color.MagentaString(s.Next()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(h.out(), "\r  \033[36mwaiting for image to become available \033[m %s", color.MagentaString(s.Next()))
 		}
-		fmt.Printf("\r  \033[36mwaiting for image to become available \033[m %s\n", color.GreenString("done !"))
+		fmt.Fprintf(h.out(), "\r  \033[36mwaiting for image to become available \033[m %s\n", color.GreenString("done !"))
 	}()
 
-	time.Sleep(20 * time.Second)
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-time.After(20 * time.Second):
+	}
 	spin = false
 
 	wg.Wait()
-	return nil
+	return err
 }
 
 // remove image from local docker and fs
-func (b *HyperBuilder) removeLocalImage() error {
-	_, err := b.DockerClient.ImageRemove(b.Context, b.BenchmarkImage, dockerTypes.ImageRemoveOptions{})
+func (b *HyperBuilder) removeLocalImage(ctx context.Context) error {
+	_, err := b.DockerClient.ImageRemove(ctx, b.BenchmarkImage, dockerTypes.ImageRemoveOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed removing benchmark image")
 	}
+	b.local = false
 
 	err = os.Remove(b.BenchmarkImage + ".tar")
 	if err != nil {
@@ -323,7 +442,7 @@ func (b *HyperBuilder) removeLocalImage() error {
 }
 
 // load image on hyper.sh
-func (b *HyperBuilder) loadOnHyper() error {
+func (b *HyperBuilder) loadOnHyper(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -333,16 +452,24 @@ func (b *HyperBuilder) loadOnHyper() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36muploading image to hyper.sh \033[m %s (%s)", color.MagentaString(s.Next()), "this may take a while.")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(b.out(), "\r  \033[36muploading image to hyper.sh \033[m %s (%s)", color.MagentaString(s.Next()), "this may take a while.")
 		}
-		fmt.Printf("\r  \033[36muploading image to hyper.sh \033[m %s (%s)\n", color.GreenString("done !"), "this may take a while.")
+	}()
 
+	// stops the spinner on every return
+	defer func() {
+		spin = false
+		wg.Wait()
 	}()
 
 	// craete image tar in order to be transferred to hyper
 	cmd := []string{"docker", "save", "-o", b.BenchmarkImage + ".tar", b.BenchmarkImage}
-	_, err := exec.Command(cmd[0], cmd[1], cmd[2], cmd[3], cmd[4]).Output()
+	_, err := exec.CommandContext(ctx, cmd[0], cmd[1], cmd[2], cmd[3], cmd[4]).Output()
 	if err != nil {
 		return errors.Wrap(err, "failed to create tar from image")
 	}
@@ -360,12 +487,15 @@ func (b *HyperBuilder) loadOnHyper() error {
 		return err
 	}
 
-	resp, err := b.HyperClient.ImageLoadLocal(b.Context, true, info.Size())
+	resp, err := b.HyperClient.ImageLoadLocal(ctx, true, info.Size())
 	if err != nil {
 		return err
 	}
 	defer resp.Conn.Close()
 
+	// partial uploads are removed on cleanup as well
+	b.uploaded = true
+
 	_, err = io.Copy(resp.Conn, tarFile)
 	if err != nil {
 		return err
@@ -373,11 +503,12 @@ func (b *HyperBuilder) loadOnHyper() error {
 
 	spin = false
 	wg.Wait()
+	fmt.Fprintf(b.out(), "\r  \033[36muploading image to hyper.sh \033[m %s (%s)\n", color.GreenString("done !"), "this may take a while.")
 	return nil
 }
 
 // pull runtime base image
-func (b *HyperBuilder) pullImage() error {
+func (b *HyperBuilder) pullImage(ctx context.Context) error {
 	var wg sync.WaitGroup
 	wg.Add(1)
 
@@ -386,16 +517,20 @@ func (b *HyperBuilder) pullImage() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36mpreparing image \033[m %s", color.MagentaString(s.Next()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(b.out(), "\r  \033[36mpreparing image \033[m %s", color.MagentaString(s.Next()))
 		}
-		fmt.Printf("\r  \033[36mpreparing image \033[m %s\n", color.GreenString("done !"))
-
 	}()
 
 	// TODO: pull images from private repos
-	out, err := b.DockerClient.ImagePull(b.Context, b.Image, dockerTypes.ImagePullOptions{})
+	out, err := b.DockerClient.ImagePull(ctx, b.Image, dockerTypes.ImagePullOptions{})
 	if err != nil {
+		spin = false
+		wg.Wait()
 		return errors.Wrap(err, "failed preparing image")
 	}
 
@@ -407,97 +542,112 @@ func (b *HyperBuilder) pullImage() error {
 				s.Reset()
 				spin = false
 				wg.Wait()
+				fmt.Fprintf(b.out(), "\r  \033[36mpreparing image \033[m %s\n", color.GreenString("done !"))
 				return nil
 			}
 			s.Reset()
 			spin = false
-			fmt.Printf("\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
+			wg.Wait()
+			fmt.Fprintf(b.out(), "\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
 			return errors.New("failed reading output")
 		}
 	}
 }
 
 // setup working dir and copy pwd dir into
-func (b *HyperBuilder) setupBaseImage() error {
+func (b *HyperBuilder) setupBaseImage(ctx context.Context) error {
 
 	config := &dockerContainer.Config{
 		Image:      b.Image,
 		WorkingDir: "/tmp",
 		OpenStdin:  true,
+		Labels:     b.Labels,
 	}
 
 	// create tmp container
-	tmpName := "ben-tmp-" + utils.RandString(8)
-	c, err := b.DockerClient.ContainerCreate(b.Context, config, nil, nil, tmpName)
+	tmpName := resourceName("ben-tmp", b.Labels)
+	c, err := b.DockerClient.ContainerCreate(ctx, config, nil, nil, tmpName)
 	if err != nil {
 		return errors.Wrap(err, "failed creating container")
 	}
+	b.tmp = c.ID
 
-	// copy pwd data into tmp container
-	cmd := []string{"docker", "cp", ".", c.ID + ":/tmp"}
-	_, err = exec.Command(cmd[0], cmd[1], cmd[2], cmd[3]).Output()
+	// copy project data into tmp container
+	archive := tarDir(b.Source)
+	defer archive.Close()
+
+	err = b.DockerClient.CopyToContainer(ctx, c.ID, "/tmp", archive, dockerTypes.CopyToContainerOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to copy data into container")
 	}
 	defer os.Remove(b.BenchmarkImage + ".tar")
 
 	// create new image
-	imageName := "ben-final-" + strings.ToLower(utils.RandString(4))
-	_, err = b.DockerClient.ContainerCommit(b.Context, c.ID, dockerTypes.ContainerCommitOptions{Reference: imageName})
+	imageName := resourceName("ben-final", b.Labels)
+	_, err = b.DockerClient.ContainerCommit(ctx, c.ID, dockerTypes.ContainerCommitOptions{Reference: imageName})
 	if err != nil {
 		return errors.Wrap(err, "failed to create benchmark image")
 	}
 
+	// save image name
+	b.BenchmarkImage = imageName
+	b.local = true
+
 	// cleanup tmp container
-	if err = b.removeContainer(c.ID); err != nil {
+	if err = b.removeContainer(ctx, c.ID); err != nil {
 		return err
 	}
-
-	// save image name
-	b.BenchmarkImage = imageName
+	b.tmp = ""
 
 	return nil
 }
 
 // run before commands if specified and create new image
-func (b *HyperBuilder) runBeforeCommands() error {
+func (b *HyperBuilder) runBeforeCommands(ctx context.Context) error {
 
 	if len(b.Before) == 0 {
-		fmt.Printf(" \033[36m no commands to run before !\n\033[m")
+		fmt.Fprintf(b.out(), " \033[36m no commands to run before !\n\033[m")
 		return nil
 	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
-	tmpName := utils.RandString(10)
+	tmpName := resourceName("ben-tmp", b.Labels)
 
 	config := &dockerContainer.Config{
 		Image:      b.BenchmarkImage,
 		WorkingDir: "/tmp",
 		OpenStdin:  true,
 		Cmd:        b.Before,
+		Env:        b.Env,
+		Labels:     b.Labels,
 	}
 
 	// create tmp container to run `before` commands
-	c, err := b.DockerClient.ContainerCreate(b.Context, config, nil, nil, tmpName)
+	c, err := b.DockerClient.ContainerCreate(ctx, config, nil, nil, tmpName)
 	if err != nil {
-		fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s ", color.RedString("failed !"))
+		fmt.Fprintf(b.out(), "\r  \033[36mrunning 'before' commands \033[m %s ", color.RedString("failed !"))
 		return errors.Wrap(err, "failed creating container")
 	}
+	b.tmp = c.ID
 
 	s := spinner.New()
 	spin := true
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(b.Before, " "))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(b.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(b.Before, " "))
 		}
 	}()
 
 	// start tmp container
-	err = b.DockerClient.ContainerStart(b.Context, c.ID, dockerTypes.ContainerStartOptions{})
+	err = b.DockerClient.ContainerStart(ctx, c.ID, dockerTypes.ContainerStartOptions{})
 	if err != nil {
 		spin = false
 		wg.Wait()
@@ -505,7 +655,7 @@ func (b *HyperBuilder) runBeforeCommands() error {
 	}
 
 	// wait until container exits
-	exit, errC := b.DockerClient.ContainerWait(b.Context, c.ID)
+	exit, errC := b.DockerClient.ContainerWait(ctx, c.ID)
 	if err := errC; err != nil {
 		spin = false
 		wg.Wait()
@@ -519,27 +669,39 @@ func (b *HyperBuilder) runBeforeCommands() error {
 		spin = false
 		wg.Wait()
 
-		fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(b.Before, " "))
+		fmt.Fprintf(b.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(b.Before, " "))
 
-		b.showOutput(c.ID)
+		b.showOutput(ctx, c.ID)
 
-		if err = b.removeContainer(c.ID); err != nil {
+		if err = b.removeContainer(ctx, c.ID); err != nil {
 			return errors.Wrap(err, "failed removing tmp container")
 		}
+		b.tmp = ""
 
-		_, err := b.DockerClient.ImageRemove(b.Context, b.BenchmarkImage, dockerTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
+		_, err := b.DockerClient.ImageRemove(ctx, b.BenchmarkImage, dockerTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
 		if err != nil {
 			return errors.Wrap(err, "failed removing benchmark image")
 		}
+		b.local = false
 
 		return errors.New("running 'before' commands failed")
 	}
 
 	oldImage := b.BenchmarkImage
 
-	// create new image
-	imageName := "ben-final-" + strings.ToLower(utils.RandString(4))
-	_, err = b.DockerClient.ContainerCommit(b.Context, c.ID, dockerTypes.ContainerCommitOptions{Reference: imageName})
+	base, _, err := b.DockerClient.ImageInspectWithRaw(ctx, oldImage)
+	if err != nil {
+		spin = false
+		wg.Wait()
+		return errors.Wrap(err, "failed inspecting benchmark image")
+	}
+
+	// create new image, without the variables of the `before` container
+	imageName := resourceName("ben-final", b.Labels)
+	_, err = b.DockerClient.ContainerCommit(ctx, c.ID, dockerTypes.ContainerCommitOptions{
+		Reference: imageName,
+		Config:    &dockerContainer.Config{Env: commitEnv(base.Config, b.Env)},
+	})
 	if err != nil {
 		spin = false
 		wg.Wait()
@@ -550,15 +712,16 @@ func (b *HyperBuilder) runBeforeCommands() error {
 	b.BenchmarkImage = imageName
 
 	// cleanup tmp container
-	if err = b.removeContainer(c.ID); err != nil {
+	if err = b.removeContainer(ctx, c.ID); err != nil {
 		spin = false
 		wg.Wait()
 
 		return err
 	}
+	b.tmp = ""
 
 	// cleanup previous image
-	_, err = b.DockerClient.ImageRemove(b.Context, oldImage, dockerTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
+	_, err = b.DockerClient.ImageRemove(ctx, oldImage, dockerTypes.ImageRemoveOptions{Force: true, PruneChildren: true})
 	if err != nil {
 		spin = false
 		wg.Wait()
@@ -569,15 +732,15 @@ func (b *HyperBuilder) runBeforeCommands() error {
 	spin = false
 	wg.Wait()
 
-	fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(b.Before, " "))
+	fmt.Fprintf(b.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(b.Before, " "))
 
 	return nil
 }
 
 // show container logs of a local container
-func (b *HyperBuilder) showOutput(containerID string) error {
+func (b *HyperBuilder) showOutput(ctx context.Context, containerID string) error {
 
-	reader, err := b.DockerClient.ContainerLogs(b.Context, containerID, dockerTypes.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	reader, err := b.DockerClient.ContainerLogs(ctx, containerID, dockerTypes.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch logs")
 	}
@@ -587,17 +750,21 @@ func (b *HyperBuilder) showOutput(containerID string) error {
 		return errors.Wrap(err, "failed to fetch logs")
 	}
 
-	fmt.Println()
-	fmt.Printf(utils.StripCtlAndExtFromUnicode(string(results)))
+	fmt.Fprintln(b.out())
+	fmt.Fprint(b.out(), utils.StripCtlAndExtFromUnicode(string(results)))
 
 	return nil
 }
 
 // Removes local container
-func (b *HyperBuilder) removeContainer(containerID string) error {
-	err := b.DockerClient.ContainerRemove(b.Context, containerID, dockerTypes.ContainerRemoveOptions{RemoveVolumes: true})
+func (b *HyperBuilder) removeContainer(ctx context.Context, containerID string) error {
+	err := b.DockerClient.ContainerRemove(ctx, containerID, dockerTypes.ContainerRemoveOptions{RemoveVolumes: true})
 	if err != nil {
 		return errors.Wrap(err, "failed removing container")
 	}
 	return nil
 }
+
+func (b *HyperBuilder) out() io.Writer {
+	return output(b.Output)
+}
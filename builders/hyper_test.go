@@ -1,6 +1,7 @@
 package builders
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -18,7 +19,7 @@ func TestBuilder_HyperBuilder_Init(t *testing.T) {
 		builder := &HyperBuilder{
 			Image: "golang:1.4",
 		}
-		err := builder.Init()
+		err := builder.Init(context.Background())
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "missing hyper.sh credentials")
 	})
@@ -35,7 +36,7 @@ func TestBuilder_HyperBuilder_Init(t *testing.T) {
 		builder := &HyperBuilder{
 			Image: "golang:1.4",
 		}
-		err := builder.Init()
+		err := builder.Init(context.Background())
 		assert.Nil(t, err)
 		assert.NotNil(t, builder.HyperClient)
 		assert.NotNil(t, builder.DockerClient)
@@ -55,7 +56,7 @@ func TestBuilder_HyperBuilder_Init(t *testing.T) {
 		builder := &HyperBuilder{
 			Image: "golang:1.4",
 		}
-		err := builder.Init()
+		err := builder.Init(context.Background())
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "invalid region set")
 	})
@@ -73,7 +74,7 @@ func TestBuilder_HyperBuilder_Init(t *testing.T) {
 		builder := &HyperBuilder{
 			Image: "golang:1.4",
 		}
-		err := builder.Init()
+		err := builder.Init(context.Background())
 		assert.Nil(t, err)
 		assert.NotNil(t, builder.HyperClient)
 		assert.NotNil(t, builder.DockerClient)
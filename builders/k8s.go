@@ -0,0 +1,585 @@
+package builders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/drish/ben/reporter"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// node label holding the node pool name on GKE
+const defaultNodePoolLabel = "cloud.google.com/gke-nodepool"
+
+// node pools are named like kubernetes labels values, ie: highcpu-8
+var nodePoolRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// K8sOptions are the `options` of k8s machines
+type K8sOptions struct {
+	Registry      string            // registry the benchmark image is pushed to, ie: gcr.io/my-project
+	Namespace     string            // namespace the jobs run on, default to the kubeconfig one
+	Context       string            // kubeconfig context, default to the current one
+	NodePoolLabel string            // node label selecting the node pool, default to cloud.google.com/gke-nodepool
+	NodeSelector  map[string]string // extra node labels the benchmark pod is scheduled on
+	Requests      map[string]string // benchmark container requests, ie: {"cpu": "2", "memory": "4Gi"}
+	Limits        map[string]string // benchmark container limits
+
+	// how long the benchmark pod may wait to be scheduled and started, default to 10m
+	ScheduleTimeout string
+}
+
+// default time the benchmark pod may be pending
+const defaultScheduleTimeout = 10 * time.Minute
+
+func (o K8sOptions) scheduleTimeout() time.Duration {
+	if d, err := time.ParseDuration(o.ScheduleTimeout); err == nil {
+		return d
+	}
+	return defaultScheduleTimeout
+}
+
+// decodes the environment options, checking they have a registry and valid resource quantities
+func k8sOptions(raw json.RawMessage) (K8sOptions, error) {
+	var o K8sOptions
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &o); err != nil {
+			return o, errors.Wrap(err, "invalid k8s options")
+		}
+	}
+
+	if o.Registry == "" {
+		return o, errors.New("k8s machines need a registry option")
+	}
+	if _, err := o.resources(); err != nil {
+		return o, err
+	}
+	if o.ScheduleTimeout != "" {
+		if d, err := time.ParseDuration(o.ScheduleTimeout); err != nil || d <= 0 {
+			return o, errors.Errorf("invalid schedule timeout: %s", o.ScheduleTimeout)
+		}
+	}
+	return o, nil
+}
+
+// resource requirements of the benchmark container
+func (o K8sOptions) resources() (corev1.ResourceRequirements, error) {
+	requests, err := resourceList(o.Requests)
+	if err != nil {
+		return corev1.ResourceRequirements{}, errors.Wrap(err, "invalid requests")
+	}
+
+	limits, err := resourceList(o.Limits)
+	if err != nil {
+		return corev1.ResourceRequirements{}, errors.Wrap(err, "invalid limits")
+	}
+
+	return corev1.ResourceRequirements{Requests: requests, Limits: limits}, nil
+}
+
+func resourceList(quantities map[string]string) (corev1.ResourceList, error) {
+	if len(quantities) == 0 {
+		return nil, nil
+	}
+
+	list := corev1.ResourceList{}
+	for name, q := range quantities {
+		parsed, err := resource.ParseQuantity(q)
+		if err != nil {
+			return nil, errors.Errorf("%s: %s", name, q)
+		}
+		list[corev1.ResourceName(name)] = parsed
+	}
+	return list, nil
+}
+
+func init() {
+	Register(Backend{
+		Prefix: "k8s",
+		Validate: func(size string) error {
+			if !nodePoolRegexp.MatchString(size) {
+				return errors.Errorf("invalid k8s node pool: %s", size)
+			}
+			return nil
+		},
+		Options: func(raw json.RawMessage) error {
+			_, err := k8sOptions(raw)
+			return err
+		},
+		New: func(opts Options) (RuntimeBuilder, error) {
+			settings, err := k8sOptions(opts.Options)
+			if err != nil {
+				return nil, err
+			}
+			return &K8sBuilder{
+				Image:    opts.Image,
+				Before:   opts.Before,
+				Command:  opts.Command,
+				Env:      opts.Env,
+				Source:   opts.Source,
+				NodePool: opts.Size,
+				Settings: settings,
+				Output:   opts.Output,
+				Labels:   opts.Labels,
+			}, nil
+		},
+	})
+}
+
+// K8sBuilder builds the benchmark image on local docker, pushes it to
+// a registry and runs the benchmark as a job on a node pool of the cluster
+type K8sBuilder struct {
+	Image          string               // runtime base image
+	Command        []string             // benchmark command
+	Before         []string             // commands to run before bench, on local docker
+	Env            []string             // environment variables of the benchmark pod, ie: GOMAXPROCS=1
+	Source         string               // project directory copied into the image, default to the working directory
+	NodePool       string               // node pool the benchmark pod is scheduled on
+	Settings       K8sOptions           // registry, namespace, resources and node selectors
+	Clientset      kubernetes.Interface // kubernetes client, set from the kubeconfig on Init when nil
+	Job            string               // benchmark job name
+	BenchmarkImage string               // benchmark image pushed to the registry
+	Results        string               // benchmark output
+	PollInterval   time.Duration        // job status polling interval, default to 2s
+	Output         io.Writer            // progress output, default to stdout
+	Labels         map[string]string    // set on the local containers, the job and its pod
+
+	local    *LocalBuilder                           // builds the benchmark image
+	readLogs func(pod string) (io.ReadCloser, error) // reads the benchmark container logs, default to the clientset
+}
+
+// Init connects to local docker and to the cluster
+func (k *K8sBuilder) Init(ctx context.Context) error {
+
+	fmt.Fprintf(k.out(), "  \033[36msetting up k8s environment for \033[m%s on node pool %s \n", k.Image, k.NodePool)
+
+	k.local = &LocalBuilder{
+		Image:  k.Image,
+		Before: k.Before,
+		Env:    k.Env,
+		Source: k.Source,
+		Output: k.Output,
+		Labels: k.Labels,
+	}
+	if err := k.local.Init(ctx); err != nil {
+		return err
+	}
+
+	if k.Clientset != nil {
+		return nil
+	}
+
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: k.Settings.Context},
+	)
+
+	rest, err := config.ClientConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed loading kubeconfig")
+	}
+
+	if k.Settings.Namespace == "" {
+		if k.Settings.Namespace, _, err = config.Namespace(); err != nil {
+			return errors.Wrap(err, "failed loading kubeconfig namespace")
+		}
+	}
+
+	k.Clientset, err = kubernetes.NewForConfig(rest)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to kubernetes")
+	}
+	return nil
+}
+
+// PrepareImage builds the benchmark image on local docker and pushes it to the registry
+func (k *K8sBuilder) PrepareImage(ctx context.Context) error {
+
+	if err := k.local.PrepareImage(ctx); err != nil {
+		return err
+	}
+
+	ref := strings.TrimSuffix(k.Settings.Registry, "/") + "/" + k.local.BenchmarkImage
+	if err := k.local.Client.ImageTag(ctx, k.local.BenchmarkImage, ref); err != nil {
+		return errors.Wrap(err, "failed tagging benchmark image")
+	}
+	k.BenchmarkImage = ref
+
+	// pushed with the docker cli, which knows the registry credentials and helpers
+	out, err := exec.CommandContext(ctx, "docker", "push", ref).CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(k.out(), "\r  \033[36mpushing image \033[m %s (%s)\n", color.RedString("failed !"), ref)
+		fmt.Fprint(k.out(), string(out))
+		return errors.Wrap(err, "failed pushing benchmark image")
+	}
+
+	fmt.Fprintf(k.out(), "\r  \033[36mpushing image \033[m %s (%s)\n", color.GreenString("done !"), ref)
+	return nil
+}
+
+// SetupContainer creates the benchmark job, its pod starts once scheduled on the node pool
+func (k *K8sBuilder) SetupContainer(ctx context.Context) error {
+
+	if k.Command == nil {
+		return errors.New("command can not be blank")
+	}
+
+	if k.BenchmarkImage == "" {
+		return errors.New("benchmark image not prepared")
+	}
+
+	job, err := k.job()
+	if err != nil {
+		return err
+	}
+
+	job, err = k.Clientset.BatchV1().Jobs(k.namespace()).Create(job)
+	if err != nil {
+		fmt.Fprintf(k.out(), "\r  \033[36mcreating benchmark job \033[m %s ", color.RedString("failed !"))
+		return errors.Wrap(err, "failed creating benchmark job")
+	}
+
+	fmt.Fprintf(k.out(), "  \033[36mcreating benchmark job \033[m %s (%s) \n", color.GreenString("done !"), job.Name)
+	k.Job = job.Name
+	return nil
+}
+
+// Benchmark waits for the job to finish and stores its pod logs, the benchmark
+// stderr is written to the termination log, shown when the job fails
+func (k *K8sBuilder) Benchmark(ctx context.Context) error {
+
+	fmt.Fprintf(k.out(), "\r  \033[36mrunning benchmark \033[m (%s)", strings.Join(k.Command, " "))
+
+	succeeded, err := k.wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	pod, err := k.pod()
+	if err != nil {
+		return err
+	}
+
+	logs, err := k.logs(pod.Name)
+	if err != nil {
+		return err
+	}
+	k.Results = logs
+
+	if !succeeded {
+		fmt.Fprintf(k.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(k.Command, " "))
+		fmt.Fprint(k.out(), terminationMessage(pod))
+		return errors.Errorf("benchmark job %s failed", k.Job)
+	}
+
+	fmt.Fprintf(k.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(k.Command, " "))
+	return nil
+}
+
+// RemoveContainer deletes the benchmark job and its pod, keeping the pushed image for further runs
+func (k *K8sBuilder) RemoveContainer(ctx context.Context) error {
+
+	if k.Job == "" {
+		return errors.New("job doesn't exist")
+	}
+
+	if err := k.deleteJob(ctx); err != nil {
+		return err
+	}
+
+	k.Job = ""
+	return nil
+}
+
+// Cleanup deletes the job and the local images, the image pushed
+// to the registry is left to its retention policy
+func (k *K8sBuilder) Cleanup(ctx context.Context) error {
+
+	var failed error
+
+	if k.Job != "" && k.Clientset != nil {
+		if err := k.deleteJob(ctx); err != nil {
+			failed = err
+		}
+		k.Job = ""
+	}
+
+	if k.BenchmarkImage != "" && k.local != nil && k.local.Client != nil {
+		_, err := k.local.Client.ImageRemove(ctx, k.BenchmarkImage, types.ImageRemoveOptions{})
+		if err != nil && failed == nil {
+			failed = errors.Wrap(err, "failed removing pushed image tag")
+		}
+	}
+	k.BenchmarkImage = ""
+
+	if k.local != nil {
+		if err := k.local.Cleanup(ctx); err != nil && failed == nil {
+			failed = err
+		}
+	}
+
+	if failed != nil {
+		return failed
+	}
+
+	fmt.Fprintln(k.out())
+	fmt.Fprintf(k.out(), "  \033[36mcleaning up job and images\033[m %s \n", color.GreenString(" done !"))
+	return nil
+}
+
+// Display writes the benchmark output to stdout
+func (k *K8sBuilder) Display() error {
+	fmt.Fprintf(k.out(), "  \033[36mdisplaying results\033[m \n")
+	fmt.Fprintln(k.out(), k.Results)
+	return nil
+}
+
+// Report returns data for being later written to fs
+func (k *K8sBuilder) Report() reporter.ReportData {
+	return reporter.ReportData{
+		Image:   k.Image,
+		Results: k.Results,
+		Machine: k.machine(),
+		Before:  strings.Join(k.Before, " "),
+		Command: strings.Join(k.Command, " "),
+	}
+}
+
+// benchmark job, run once on the node pool without retries. The benchmark
+// stderr goes to the termination log, so the pod logs are its stdout only
+func (k *K8sBuilder) job() (*batchv1.Job, error) {
+
+	resources, err := k.Settings.resources()
+	if err != nil {
+		return nil, err
+	}
+
+	label := k.Settings.NodePoolLabel
+	if label == "" {
+		label = defaultNodePoolLabel
+	}
+	selector := map[string]string{label: k.NodePool}
+	for l, v := range k.Settings.NodeSelector {
+		selector[l] = v
+	}
+
+	var env []corev1.EnvVar
+	for _, e := range k.Env {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid environment variable: %s, expected NAME=value", e)
+		}
+		env = append(env, corev1.EnvVar{Name: parts[0], Value: parts[1]})
+	}
+
+	backoff := int32(0)
+	meta := metav1.ObjectMeta{
+		Name:      resourceName("ben", k.Labels),
+		Namespace: k.namespace(),
+		Labels:    k.Labels,
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: meta,
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoff,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: k.Labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					NodeSelector:  selector,
+					Containers: []corev1.Container{{
+						Name:       "benchmark",
+						Image:      k.BenchmarkImage,
+						Command:    []string{"sh", "-c", `exec "$@" 2>` + corev1.TerminationMessagePathDefault, "sh"},
+						Args:       k.Command,
+						WorkingDir: "/tmp",
+						Env:        env,
+						Resources:  resources,
+					}},
+				},
+			},
+		},
+	}, nil
+}
+
+// polls the job until it succeeds or fails, failing when its pod
+// is still pending once the schedule timeout is over
+func (k *K8sBuilder) wait(ctx context.Context) (bool, error) {
+	interval := k.PollInterval
+	if interval == 0 {
+		interval = 2 * time.Second
+	}
+
+	timeout := k.Settings.scheduleTimeout()
+	start := time.Now()
+
+	for {
+		job, err := k.Clientset.BatchV1().Jobs(k.namespace()).Get(k.Job, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get job status")
+		}
+
+		switch {
+		case job.Status.Succeeded > 0:
+			return true, nil
+		case job.Status.Failed > 0:
+			return false, nil
+		}
+
+		if time.Since(start) > timeout {
+			if err := k.pending(timeout); err != nil {
+				return false, err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// fails when the job pod isn't running yet, with the reason it's pending
+func (k *K8sBuilder) pending(timeout time.Duration) error {
+	pod, err := k.pod()
+	if err != nil {
+		return errors.Wrapf(err, "benchmark job %s not started after %s", k.Job, timeout)
+	}
+
+	if pod.Status.Phase != corev1.PodPending {
+		return nil
+	}
+	return errors.Errorf("benchmark pod %s still pending after %s: %s", pod.Name, timeout, pendingReason(pod))
+}
+
+// pod of the job, the job doesn't retry so there's only one
+func (k *K8sBuilder) pod() (*corev1.Pod, error) {
+	list, err := k.Clientset.CoreV1().Pods(k.namespace()).List(metav1.ListOptions{LabelSelector: "job-name=" + k.Job})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing job pods")
+	}
+	if len(list.Items) == 0 {
+		return nil, errors.Errorf("job %s has no pods", k.Job)
+	}
+	return &list.Items[0], nil
+}
+
+// logs of the benchmark container
+func (k *K8sBuilder) logs(pod string) (string, error) {
+	read := k.readLogs
+	if read == nil {
+		read = func(pod string) (io.ReadCloser, error) {
+			return k.Clientset.CoreV1().Pods(k.namespace()).GetLogs(pod, &corev1.PodLogOptions{Container: "benchmark"}).Stream()
+		}
+	}
+
+	stream, err := read(pod)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to fetch logs")
+	}
+	defer stream.Close()
+
+	b, err := ioutil.ReadAll(stream)
+	if err != nil {
+		return "", errors.Wrap(err, "failed reading logs")
+	}
+	return string(b), nil
+}
+
+// deletes the job along with its pods, jobs already gone are fine
+func (k *K8sBuilder) deleteJob(ctx context.Context) error {
+	propagation := metav1.DeletePropagationBackground
+	err := k.Clientset.BatchV1().Jobs(k.namespace()).Delete(k.Job, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed deleting benchmark job")
+	}
+	return nil
+}
+
+func (k *K8sBuilder) namespace() string {
+	if k.Settings.Namespace == "" {
+		return "default"
+	}
+	return k.Settings.Namespace
+}
+
+// node pool machine along with its resources, ie: k8s-highcpu (requests cpu=2, limits cpu=2)
+func (k *K8sBuilder) machine() string {
+	m := "k8s-" + k.NodePool
+
+	var res []string
+	if len(k.Settings.Requests) > 0 {
+		res = append(res, "requests "+quantities(k.Settings.Requests))
+	}
+	if len(k.Settings.Limits) > 0 {
+		res = append(res, "limits "+quantities(k.Settings.Limits))
+	}
+	if len(res) > 0 {
+		m += " (" + strings.Join(res, ", ") + ")"
+	}
+	return m
+}
+
+// sorted resource quantities, ie: cpu=2 memory=4Gi
+func quantities(q map[string]string) string {
+	var s []string
+	for name, v := range q {
+		s = append(s, name+"="+v)
+	}
+	sort.Strings(s)
+	return strings.Join(s, " ")
+}
+
+func (k *K8sBuilder) out() io.Writer {
+	return output(k.Output)
+}
+
+// why the pod is pending, ie: Unschedulable, 0/3 nodes are available: 3 Insufficient cpu.
+func pendingReason(pod *corev1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			return reason(c.Reason, c.Message)
+		}
+	}
+	for _, c := range pod.Status.ContainerStatuses {
+		if w := c.State.Waiting; w != nil {
+			return reason(w.Reason, w.Message)
+		}
+	}
+	return "unknown reason"
+}
+
+func reason(reason, message string) string {
+	if message == "" {
+		return reason
+	}
+	return reason + ", " + message
+}
+
+// benchmark stderr, kubernetes keeps the last 4KB of it
+func terminationMessage(pod *corev1.Pod) string {
+	for _, c := range pod.Status.ContainerStatuses {
+		if t := c.State.Terminated; t != nil && c.Name == "benchmark" {
+			return t.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,248 @@
+package builders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// creates the job pod with `status`, as the cluster would once the job is scheduled
+func createPod(t *testing.T, k *K8sBuilder, status corev1.PodStatus) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      k.Job + "-x1",
+			Namespace: k.namespace(),
+			Labels:    map[string]string{"job-name": k.Job},
+		},
+		Status: status,
+	}
+	_, err := k.Clientset.CoreV1().Pods(k.namespace()).Create(pod)
+	assert.Nil(t, err)
+}
+
+// completes the job as the cluster would, its pod terminated with `stderr` as termination message
+func finishJob(t *testing.T, k *K8sBuilder, failed bool, stderr string) {
+	jobs := k.Clientset.BatchV1().Jobs(k.namespace())
+
+	job, err := jobs.Get(k.Job, metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	if failed {
+		job.Status.Failed = 1
+	} else {
+		job.Status.Succeeded = 1
+	}
+	_, err = jobs.UpdateStatus(job)
+	assert.Nil(t, err)
+
+	createPod(t, k, corev1.PodStatus{
+		Phase: corev1.PodSucceeded,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "benchmark",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: stderr}},
+		}},
+	})
+}
+
+func newTestK8sBuilder() *K8sBuilder {
+	return &K8sBuilder{
+		Image:          "golang:1.9",
+		Command:        []string{"go", "test", "-bench=."},
+		Env:            []string{"GOMAXPROCS=2"},
+		NodePool:       "highcpu",
+		BenchmarkImage: "gcr.io/bench/ben-final-abcd",
+		Settings: K8sOptions{
+			Registry:     "gcr.io/bench",
+			Namespace:    "perf",
+			NodeSelector: map[string]string{"disktype": "ssd"},
+			Requests:     map[string]string{"cpu": "2", "memory": "4Gi"},
+			Limits:       map[string]string{"cpu": "2"},
+		},
+		Labels:       Labels("abc123", 0, "0.1.0"),
+		Clientset:    fake.NewSimpleClientset(),
+		PollInterval: time.Millisecond,
+		Output:       ioutil.Discard,
+		readLogs: func(pod string) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader("fake logs")), nil
+		},
+	}
+}
+
+func TestK8s_Job(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("runs on the node pool with the resources", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		assert.Nil(t, k.SetupContainer(ctx))
+		assert.Equal(t, strings.HasPrefix(k.Job, "ben-abc123-0-"), true)
+
+		job, err := k.Clientset.BatchV1().Jobs("perf").Get(k.Job, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, job.Labels[LabelRunID], "abc123")
+		assert.Equal(t, *job.Spec.BackoffLimit, int32(0))
+
+		pod := job.Spec.Template.Spec
+		assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+		assert.Equal(t, pod.NodeSelector, map[string]string{"cloud.google.com/gke-nodepool": "highcpu", "disktype": "ssd"})
+
+		c := pod.Containers[0]
+		assert.Equal(t, c.Image, "gcr.io/bench/ben-final-abcd")
+		assert.Equal(t, c.Command, []string{"sh", "-c", `exec "$@" 2>/dev/termination-log`, "sh"})
+		assert.Equal(t, c.Args, []string{"go", "test", "-bench=."})
+		assert.Equal(t, c.Env, []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}})
+		assert.Equal(t, c.Resources.Requests.Memory().String(), "4Gi")
+		assert.Equal(t, c.Resources.Limits.Cpu().String(), "2")
+	})
+
+	t.Run("custom node pool label", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		k.Settings.NodePoolLabel = "eks.amazonaws.com/nodegroup"
+		k.Settings.NodeSelector = nil
+
+		job, err := k.job()
+		assert.Nil(t, err)
+		assert.Equal(t, job.Spec.Template.Spec.NodeSelector, map[string]string{"eks.amazonaws.com/nodegroup": "highcpu"})
+	})
+
+	t.Run("invalid environment variable", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		k.Env = []string{"GOMAXPROCS=2", "EMPTY=", "BROKEN"}
+
+		err := k.SetupContainer(ctx)
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "invalid environment variable: BROKEN, expected NAME=value")
+
+		jobs, err := k.Clientset.BatchV1().Jobs("perf").List(metav1.ListOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(jobs.Items), 0)
+	})
+
+	t.Run("collects logs and deletes the job", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		assert.Nil(t, k.SetupContainer(ctx))
+		name := k.Job
+
+		finishJob(t, k, false, "")
+		assert.Nil(t, k.Benchmark(ctx))
+		assert.Equal(t, k.Results, "fake logs")
+
+		assert.Nil(t, k.Cleanup(ctx))
+		assert.Equal(t, k.Job, "")
+
+		_, err := k.Clientset.BatchV1().Jobs("perf").Get(name, metav1.GetOptions{})
+		assert.Equal(t, apierrors.IsNotFound(err), true)
+	})
+
+	t.Run("failed job", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		var out bytes.Buffer
+		k.Output = &out
+		assert.Nil(t, k.SetupContainer(ctx))
+
+		finishJob(t, k, true, "panic: boom\n")
+		err := k.Benchmark(ctx)
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "benchmark job "+k.Job+" failed")
+
+		// stderr is shown on the progress output only
+		assert.Equal(t, k.Results, "fake logs")
+		assert.Contains(t, out.String(), "panic: boom\n")
+
+		assert.Nil(t, k.RemoveContainer(ctx))
+	})
+
+	t.Run("pending pod", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		k.Settings.ScheduleTimeout = "1ms"
+		assert.Nil(t, k.SetupContainer(ctx))
+
+		createPod(t, k, corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			}},
+		})
+		err := k.Benchmark(ctx)
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "benchmark pod "+k.Job+"-x1 still pending after 1ms: Unschedulable, 0/3 nodes are available: 3 Insufficient cpu.")
+	})
+
+	t.Run("pending image", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		k.Settings.ScheduleTimeout = "1ms"
+		assert.Nil(t, k.SetupContainer(ctx))
+
+		createPod(t, k, corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}},
+		})
+		err := k.Benchmark(ctx)
+		assert.NotNil(t, err)
+		assert.Equal(t, strings.HasSuffix(err.Error(), ": ImagePullBackOff"), true)
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		k := newTestK8sBuilder()
+		assert.Nil(t, k.SetupContainer(ctx))
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Equal(t, k.Benchmark(canceled), context.Canceled)
+	})
+}
+
+func TestK8s_Machine(t *testing.T) {
+	assert.Nil(t, ValidateMachine("k8s-highcpu"))
+	assert.NotNil(t, ValidateMachine("k8s"))
+	assert.NotNil(t, ValidateMachine("k8s-High_CPU"))
+
+	_, err := New(Options{Machine: "k8s-highcpu"})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "k8s machines need a registry option")
+
+	b, err := New(Options{Machine: "k8s-highcpu", Options: json.RawMessage(`{"registry": "gcr.io/bench", "namespace": "perf"}`)})
+	assert.Nil(t, err)
+	assert.Equal(t, b.(*K8sBuilder).Settings.Namespace, "perf")
+
+	k := newTestK8sBuilder()
+	assert.Equal(t, k.machine(), "k8s-highcpu (requests cpu=2 memory=4Gi, limits cpu=2)")
+}
+
+func TestK8s_Options(t *testing.T) {
+	o, err := k8sOptions(json.RawMessage(`{"registry": "gcr.io/bench", "requests": {"cpu": "500m", "memory": "1.5Gi"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, o.Requests["cpu"], "500m")
+
+	err = Check(Spec{Machine: "k8s-highcpu", Options: json.RawMessage(`{"registry": "gcr.io/bench", "limits": {"memory": "lots"}}`)})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "invalid limits: memory: lots")
+
+	err = Check(Spec{Machine: "k8s-highcpu", Options: json.RawMessage(`{"registry": "gcr.io/bench", "scheduleTimeout": "soon"}`)})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "invalid schedule timeout: soon")
+
+	err = Check(Spec{Machine: "k8s-highcpu", Options: json.RawMessage(`{"registry": 1}`)})
+	assert.NotNil(t, err)
+	assert.Equal(t, strings.HasPrefix(err.Error(), "invalid k8s options: "), true)
+
+	err = Check(Spec{Machine: "local", Options: json.RawMessage(`{"registry": "gcr.io/bench"}`)})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "options aren't supported on local machines")
+}
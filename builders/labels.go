@@ -0,0 +1,49 @@
+package builders
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/drish/ben/utils"
+)
+
+// labels set on every container and image created by ben, so
+// resources leaked by failed or killed runs can be found later
+const (
+	LabelRunID       = "ben.run-id"
+	LabelEnvironment = "ben.environment"
+	LabelVersion     = "ben.version"
+)
+
+// Labels returns the labels of the resources created for the environment `env` of a run
+func Labels(runID string, env int, version string) map[string]string {
+	return map[string]string{
+		LabelRunID:       runID,
+		LabelEnvironment: strconv.Itoa(env),
+		LabelVersion:     version,
+	}
+}
+
+// returns a copy of `labels` with `extra` added
+func withLabels(labels map[string]string, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(labels)+len(extra))
+	for k, v := range labels {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// returns a docker friendly name for a resource of the environment, starting with `prefix`
+// and made unique by the run id, the environment index and a random suffix
+// example output
+// ben-final-kxqazmdcrtyp-0-hqzkwbnf
+func resourceName(prefix string, labels map[string]string) string {
+	name := prefix
+	if id := labels[LabelRunID]; id != "" {
+		name += "-" + id + "-" + labels[LabelEnvironment]
+	}
+	return name + "-" + strings.ToLower(utils.RandString(8))
+}
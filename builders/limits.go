@@ -0,0 +1,144 @@
+package builders
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+// cpu quota period used for cpu limits, in microseconds
+const cpuPeriod = 100000
+
+// local profiles, ie: local, local-2cpu, local-512m, local-2cpu-1g
+var profileRegexp = regexp.MustCompile(`^local(?:-(\d+(?:\.\d+)?)cpus?)?(?:-(\d+[kmg]b?))?$`)
+
+var memoryRegexp = regexp.MustCompile(`^(\d+)([kmg])b?$`)
+
+var memoryUnits = map[string]int64{
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+}
+
+// Limits restricts the resources of local benchmark containers, zero values are unlimited
+type Limits struct {
+	CPUs   float64 // number of cpus, ie: 1.5
+	Memory int64   // memory in bytes, swap is disabled when set
+	Cpuset string  // cpus the container runs on, ie: 0-1
+}
+
+// prefix of local profiles emulating hyper sizes, ie: local-as-hyper-s4
+const emulatePrefix = "local-as-hyper-"
+
+// ParseProfile returns the limits of a local profile
+func ParseProfile(machine string) (Limits, error) {
+	var l Limits
+
+	// emulated sizes match the hyper ones cpu and memory
+	if strings.HasPrefix(machine, emulatePrefix) {
+		size, ok := hyperSizes[strings.TrimPrefix(machine, emulatePrefix)]
+		if !ok {
+			return l, errors.Errorf("invalid local profile: %s", machine)
+		}
+		return size, nil
+	}
+
+	m := profileRegexp.FindStringSubmatch(machine)
+	if m == nil {
+		return l, errors.Errorf("invalid local profile: %s", machine)
+	}
+
+	if m[1] != "" {
+		l.CPUs, _ = strconv.ParseFloat(m[1], 64)
+	}
+
+	if m[2] != "" {
+		mem, err := ParseMemory(m[2])
+		if err != nil {
+			return l, err
+		}
+		l.Memory = mem
+	}
+
+	return l, nil
+}
+
+// ParseMemory parses memory sizes, ie: 512m, 1g
+func ParseMemory(s string) (int64, error) {
+	m := memoryRegexp.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, errors.Errorf("invalid memory: %s", s)
+	}
+
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid memory: %s", s)
+	}
+	return n * memoryUnits[m[2]], nil
+}
+
+// IsZero reports whether there's no limit set
+func (l Limits) IsZero() bool {
+	return l.CPUs == 0 && l.Memory == 0 && l.Cpuset == ""
+}
+
+// String describes the limits like the hyper sizes, ie: 2 CPU 1GB
+func (l Limits) String() string {
+	var parts []string
+
+	if l.CPUs > 0 {
+		parts = append(parts, strconv.FormatFloat(l.CPUs, 'f', -1, 64)+" CPU")
+	}
+
+	switch {
+	case l.Memory == 0:
+	case l.Memory%memoryUnits["g"] == 0:
+		parts = append(parts, strconv.FormatInt(l.Memory/memoryUnits["g"], 10)+"GB")
+	case l.Memory%memoryUnits["m"] == 0:
+		parts = append(parts, strconv.FormatInt(l.Memory/memoryUnits["m"], 10)+"MB")
+	default:
+		parts = append(parts, strconv.FormatInt(l.Memory/memoryUnits["k"], 10)+"KB")
+	}
+
+	if l.Cpuset != "" {
+		parts = append(parts, "cpuset "+l.Cpuset)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// returns the limits with the ones set on `o` taking precedence
+func (l Limits) override(o Limits) Limits {
+	if o.CPUs > 0 {
+		l.CPUs = o.CPUs
+	}
+	if o.Memory > 0 {
+		l.Memory = o.Memory
+	}
+	if o.Cpuset != "" {
+		l.Cpuset = o.Cpuset
+	}
+	return l
+}
+
+// container host config applying the limits, nil when unlimited
+func (l Limits) hostConfig() *container.HostConfig {
+	if l.IsZero() {
+		return nil
+	}
+
+	h := &container.HostConfig{}
+	if l.CPUs > 0 {
+		h.CPUPeriod = cpuPeriod
+		h.CPUQuota = int64(l.CPUs * cpuPeriod)
+	}
+	if l.Memory > 0 {
+		h.Memory = l.Memory
+		h.MemorySwap = l.Memory
+	}
+	h.CpusetCpus = l.Cpuset
+	return h
+}
@@ -0,0 +1,64 @@
+package builders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits_ParseProfile(t *testing.T) {
+
+	t.Run("local", func(t *testing.T) {
+		l, err := ParseProfile("local")
+		assert.Nil(t, err)
+		assert.Equal(t, l.IsZero(), true)
+		assert.Nil(t, l.hostConfig())
+	})
+
+	t.Run("cpus and memory", func(t *testing.T) {
+		l, err := ParseProfile("local-2cpu-1g")
+		assert.Nil(t, err)
+		assert.Equal(t, l, Limits{CPUs: 2, Memory: 1 << 30})
+		assert.Equal(t, l.String(), "2 CPU 1GB")
+	})
+
+	t.Run("fractional cpus", func(t *testing.T) {
+		l, err := ParseProfile("local-0.5cpu")
+		assert.Nil(t, err)
+		assert.Equal(t, l.CPUs, 0.5)
+		assert.Equal(t, l.hostConfig().CPUQuota, int64(50000))
+	})
+
+	t.Run("memory only", func(t *testing.T) {
+		l, err := ParseProfile("local-512m")
+		assert.Nil(t, err)
+		assert.Equal(t, l.String(), "512MB")
+		assert.Equal(t, l.hostConfig().MemorySwap, int64(512<<20))
+	})
+
+	t.Run("hyper emulation", func(t *testing.T) {
+		l, err := ParseProfile("local-as-hyper-s4")
+		assert.Nil(t, err)
+		assert.Equal(t, l, Limits{CPUs: 1, Memory: 512 << 20})
+		assert.Equal(t, sizeDescription("s4"), "s4 - 1 CPU 512MB")
+
+		_, err = ParseProfile("local-as-hyper-s9")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseProfile("local-fast")
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "invalid local profile: local-fast")
+	})
+}
+
+func TestLimits_ParseMemory(t *testing.T) {
+	m, err := ParseMemory("256MB")
+	assert.Nil(t, err)
+	assert.Equal(t, m, int64(256<<20))
+
+	_, err = ParseMemory("1t")
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "invalid memory: 1t")
+}
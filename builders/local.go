@@ -2,11 +2,11 @@ package builders
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os/exec"
 	"strings"
 	"sync"
 	"time"
@@ -15,7 +15,6 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/drish/ben/reporter"
-	"github.com/drish/ben/utils"
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	spinner "github.com/tj/go-spin"
@@ -23,49 +22,93 @@ import (
 
 // LocalBuilder is the local struct for managing with local runtimes
 type LocalBuilder struct {
-	Image          string          // runtime base image
-	Command        []string        // benchmark command
-	Before         []string        // commands to run before bench
-	ID             string          // benchmark container id
-	Client         *client.Client  // docker client
-	Results        string          // benchmark output
-	BenchmarkImage string          // if `before` is set a new image is created
-	Context        context.Context // context background
-	DockerVersion  types.Version   // docker info
+	Image          string            // runtime base image
+	Command        []string          // benchmark command
+	Before         []string          // commands to run before bench
+	Env            []string          // environment variables of the before and benchmark containers, ie: GOMAXPROCS=1
+	Source         string            // project directory copied into the image, default to the working directory
+	Machine        string            // local profile, ie: local-2cpu-1g, default to local
+	Limits         Limits            // resources of the benchmark container, unlimited by default
+	ID             string            // benchmark container id
+	Client         *client.Client    // docker client
+	Host           string            // docker compatible daemon socket, default to the DOCKER_HOST environment
+	Results        string            // benchmark output
+	ExitCode       int               // exit code of the benchmark command
+	BenchmarkImage string            // if `before` is set a new image is created
+	DockerVersion  types.Version     // docker info
+	Output         io.Writer         // progress output, default to stdout
+	Labels         map[string]string // set on every container, committed images inherit them
+
+	tmp string // temporary container, removed on cleanup if left behind
 }
 
-// Init initializes necessary variables
-func (l *LocalBuilder) Init() error {
+func init() {
+	Register(Backend{
+		Prefix: "local",
+		Validate: func(size string) error {
+			_, err := ParseProfile(localMachine(size))
+			return err
+		},
+		Limits: true,
+		Local:  true,
+		New: func(opts Options) (RuntimeBuilder, error) {
+			limits, err := ParseProfile(opts.Machine)
+			if err != nil {
+				return nil, err
+			}
+			return &LocalBuilder{
+				Image:   opts.Image,
+				Before:  opts.Before,
+				Command: opts.Command,
+				Env:     opts.Env,
+				Source:  opts.Source,
+				Machine: opts.Machine,
+				Limits:  limits.override(opts.Limits),
+				Output:  opts.Output,
+				Labels:  opts.Labels,
+			}, nil
+		},
+	})
+}
+
+// local machine of the size, ie: local-2cpu-1g
+func localMachine(size string) string {
+	if size == "" {
+		return "local"
+	}
+	return "local-" + size
+}
 
-	fmt.Printf("  \033[36msetting up local environment for \033[m%s \n", l.Image)
+// Init initializes necessary variables
+func (l *LocalBuilder) Init(ctx context.Context) error {
 
-	cli, err := client.NewEnvClient()
+	fmt.Fprintf(l.out(), "  \033[36msetting up local environment for \033[m%s \n", l.Image)
 
+	cli, err := newDockerClient(l.Host)
 	if err != nil {
 		return errors.Wrap(err, "failed to connect to local docker")
 	}
 
 	l.Client = cli
-	l.Context = context.Background()
 
-	version, err := l.Client.ServerVersion(l.Context)
+	version, err := l.Client.ServerVersion(ctx)
 	l.DockerVersion = version
 
 	return nil
 }
 
 // PrepareImage pulls the base image and run `before` commands
-func (l *LocalBuilder) PrepareImage() error {
+func (l *LocalBuilder) PrepareImage(ctx context.Context) error {
 
-	if err := l.pullImage(); err != nil {
+	if err := l.pullImage(ctx); err != nil {
 		return err
 	}
 
-	if err := l.setupBaseImage(); err != nil {
+	if err := l.setupBaseImage(ctx); err != nil {
 		return err
 	}
 
-	if err := l.runBeforeCommands(); err != nil {
+	if err := l.runBeforeCommands(ctx); err != nil {
 		return err
 	}
 
@@ -73,7 +116,7 @@ func (l *LocalBuilder) PrepareImage() error {
 }
 
 // SetupContainer creates the final benchmark container locally
-func (l *LocalBuilder) SetupContainer() error {
+func (l *LocalBuilder) SetupContainer(ctx context.Context) error {
 
 	if l.Command == nil {
 		return errors.New("command can not be blank")
@@ -88,21 +131,24 @@ func (l *LocalBuilder) SetupContainer() error {
 		WorkingDir: "/tmp",
 		OpenStdin:  true,
 		Cmd:        l.Command,
+		Env:        l.Env,
+		Labels:     l.Labels,
 	}
 
-	c, err := l.Client.ContainerCreate(l.Context, config, nil, nil, "")
+	// only the benchmark container is limited, `before` commands run unrestricted
+	c, err := l.Client.ContainerCreate(ctx, config, l.Limits.hostConfig(), nil, "")
 	if err != nil {
-		fmt.Printf("\r  \033[36mcreating benchmark container \033[m %s ", color.RedString("failed !"))
+		fmt.Fprintf(l.out(), "\r  \033[36mcreating benchmark container \033[m %s ", color.RedString("failed !"))
 		return errors.Wrap(err, "failed creating benchmark container")
 	}
 
-	fmt.Printf("  \033[36mcreating benchmark container \033[m %s (%s) \n", color.GreenString("done !"), c.ID[:10])
+	fmt.Fprintf(l.out(), "  \033[36mcreating benchmark container \033[m %s (%s) \n", color.GreenString("done !"), c.ID[:10])
 	l.ID = c.ID
 	return nil
 }
 
 // Benchmark runs the benchmark command
-func (l *LocalBuilder) Benchmark() error {
+func (l *LocalBuilder) Benchmark(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -112,69 +158,119 @@ func (l *LocalBuilder) Benchmark() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(200 * time.Millisecond)
-			fmt.Printf("\r  \033[36mrunning benchmark \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(l.Command, " "))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(200 * time.Millisecond):
+			}
+			fmt.Fprintf(l.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(l.Command, " "))
 		}
-		fmt.Printf("\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(l.Command, " "))
+	}()
 
+	// stops the spinner on every return
+	defer func() {
+		spin = false
+		wg.Wait()
 	}()
 
 	// start container
-	err := l.Client.ContainerStart(l.Context, l.ID, types.ContainerStartOptions{})
+	err := l.Client.ContainerStart(ctx, l.ID, types.ContainerStartOptions{})
 	if err != nil {
 		return errors.Wrap(err, "couldn't start container")
 	}
 
 	// wait until container exits
-	_, errC := l.Client.ContainerWait(l.Context, l.ID)
-	if err := errC; err != nil {
+	status, err := l.Client.ContainerWait(ctx, l.ID)
+	if err != nil {
 		return errors.Wrap(err, "failed to wait for container status")
 	}
 
-	// store container logs
-	// using exec here because was having problems with encoding on ContainerLogs
-	cmd := []string{"docker", "logs", l.ID}
-	out, err := exec.Command(cmd[0], cmd[1], cmd[2]).Output()
+	// store container stdout, logs are multiplexed with stderr
+	logs, err := l.Client.ContainerLogs(ctx, l.ID, types.ContainerLogsOptions{ShowStdout: true})
 	if err != nil {
-		return errors.Wrap(err, "failed to copy data into container")
+		return errors.Wrap(err, "failed to fetch logs")
 	}
+	defer logs.Close()
 
-	l.Results = string(out)
+	var out bytes.Buffer
+	if err := demuxLogs(logs, &out); err != nil {
+		return err
+	}
+
+	l.Results = out.String()
+	l.ExitCode = int(status)
 	spin = false
 	s.Reset()
 
 	wg.Wait()
+	if l.ExitCode != 0 {
+		fmt.Fprintf(l.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(l.Command, " "))
+		return errors.Errorf("benchmark command exited with %d", l.ExitCode)
+	}
+	fmt.Fprintf(l.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(l.Command, " "))
 	return nil
 }
 
-// Cleanup cleans up containers used for benchmarking
-func (l *LocalBuilder) Cleanup() error {
+// RemoveContainer removes the benchmark container, keeping the benchmark image for further runs
+func (l *LocalBuilder) RemoveContainer(ctx context.Context) error {
 
 	if l.ID == "" {
 		return errors.New("container doesn't exist")
 	}
 
-	// try to remove benchmark container
-	err := l.Client.ContainerRemove(l.Context, l.ID, types.ContainerRemoveOptions{RemoveVolumes: true})
-	if err != nil {
-		return errors.Wrap(err, "failed removing container")
+	if err := l.removeContainer(ctx, l.ID); err != nil {
+		return err
+	}
+
+	l.ID = ""
+	return nil
+}
+
+// Cleanup removes the containers and image used for benchmarking,
+// it's safe to call at any point and only removes what was created
+func (l *LocalBuilder) Cleanup(ctx context.Context) error {
+
+	// nothing was created before connecting to docker
+	if l.Client == nil {
+		return nil
 	}
 
+	var failed error
+
+	// containers still running after an interrupt are killed
+	for _, id := range []string{l.tmp, l.ID} {
+		if id == "" {
+			continue
+		}
+		err := l.Client.ContainerRemove(ctx, id, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		if err != nil && failed == nil {
+			failed = errors.Wrap(err, "failed removing container")
+		}
+	}
+	l.tmp, l.ID = "", ""
+
 	// delete the image
-	_, err = l.Client.ImageRemove(l.Context, l.BenchmarkImage, types.ImageRemoveOptions{})
-	if err != nil {
-		return errors.Wrap(err, "failed removing benchmark image")
+	if l.BenchmarkImage != "" {
+		_, err := l.Client.ImageRemove(ctx, l.BenchmarkImage, types.ImageRemoveOptions{})
+		if err != nil && failed == nil {
+			failed = errors.Wrap(err, "failed removing benchmark image")
+		}
+		l.BenchmarkImage = ""
+	}
+
+	if failed != nil {
+		return failed
 	}
 
-	fmt.Println()
-	fmt.Printf("  \033[36mcleaning up container and volumes\033[m %s \n", color.GreenString(" done !"))
+	fmt.Fprintln(l.out())
+	fmt.Fprintf(l.out(), "  \033[36mcleaning up container and volumes\033[m %s \n", color.GreenString(" done !"))
 	return nil
 }
 
 // Display writes the benchmark output to stdout
 func (l *LocalBuilder) Display() error {
-	fmt.Printf("  \033[36mdisplaying results\033[m \n")
-	fmt.Println(l.Results)
+	fmt.Fprintf(l.out(), "  \033[36mdisplaying results\033[m \n")
+	fmt.Fprintln(l.out(), l.Results)
 	return nil
 }
 
@@ -183,7 +279,7 @@ func (l *LocalBuilder) Report() reporter.ReportData {
 	d := reporter.ReportData{
 		Image:   l.Image,
 		Results: l.Results,
-		Machine: "local",
+		Machine: l.machine(),
 		Before:  strings.Join(l.Before, " "),
 		Command: strings.Join(l.Command, " "),
 		V:       l.DockerVersion.Version,
@@ -196,7 +292,7 @@ func (l *LocalBuilder) Report() reporter.ReportData {
 }
 
 // pull runtime image
-func (l *LocalBuilder) pullImage() error {
+func (l *LocalBuilder) pullImage(ctx context.Context) error {
 	var wg sync.WaitGroup
 	wg.Add(1)
 
@@ -205,18 +301,22 @@ func (l *LocalBuilder) pullImage() error {
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36mpreparing image \033[m %s", color.MagentaString(s.Next()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(l.out(), "\r  \033[36mpreparing image \033[m %s", color.MagentaString(s.Next()))
 		}
-		fmt.Printf("\r  \033[36mpreparing image \033[m %s\n", color.GreenString("done !"))
-
 	}()
 
 	// pulls runtime image
 	// TODO: pull images from private repos
-	out, err := l.Client.ImagePull(l.Context, l.Image, types.ImagePullOptions{})
+	out, err := l.Client.ImagePull(ctx, l.Image, types.ImagePullOptions{})
 	if err != nil {
-		fmt.Printf("\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
+		spin = false
+		wg.Wait()
+		fmt.Fprintf(l.out(), "\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
 		return errors.Wrap(err, "failed preparing image")
 	}
 
@@ -228,96 +328,139 @@ func (l *LocalBuilder) pullImage() error {
 				s.Reset()
 				spin = false
 				wg.Wait()
+				fmt.Fprintf(l.out(), "\r  \033[36mpreparing image \033[m %s\n", color.GreenString("done !"))
 				return nil
 			}
 			s.Reset()
 			spin = false
-			fmt.Printf("\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
+			wg.Wait()
+			fmt.Fprintf(l.out(), "\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
 			return errors.New("failed reading output")
 		}
 	}
 }
 
 // setup working dir and copy pwd dir into
-func (l *LocalBuilder) setupBaseImage() error {
+func (l *LocalBuilder) setupBaseImage(ctx context.Context) error {
 
 	config := &container.Config{
 		Image:      l.Image,
 		WorkingDir: "/tmp",
 		OpenStdin:  true,
+		Labels:     l.Labels,
 	}
 
 	// create tmp container
-	tmpName := "ben-tmp-" + utils.RandString(8)
-	c, err := l.Client.ContainerCreate(l.Context, config, nil, nil, tmpName)
+	tmpName := resourceName("ben-tmp", l.Labels)
+	c, err := l.Client.ContainerCreate(ctx, config, nil, nil, tmpName)
 	if err != nil {
 		return errors.Wrap(err, "failed creating container")
 	}
+	l.tmp = c.ID
+
+	// copy project data into tmp container
+	archive := tarDir(l.Source)
+	defer archive.Close()
 
-	// copy pwd data into tmp container
-	cmd := []string{"docker", "cp", ".", c.ID + ":/tmp"}
-	_, err = exec.Command(cmd[0], cmd[1], cmd[2], cmd[3]).Output()
+	err = l.Client.CopyToContainer(ctx, c.ID, "/tmp", archive, types.CopyToContainerOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to copy data into container")
 	}
 
 	// create new image
-	imageName := "ben-final-" + strings.ToLower(utils.RandString(4))
-	_, err = l.Client.ContainerCommit(l.Context, c.ID, types.ContainerCommitOptions{Reference: imageName})
+	imageName := resourceName("ben-final", l.Labels)
+	_, err = l.Client.ContainerCommit(ctx, c.ID, types.ContainerCommitOptions{Reference: imageName})
 	if err != nil {
 		return errors.Wrap(err, "failed to create benchmark image")
 	}
 
+	// save image name
+	l.BenchmarkImage = imageName
+
 	// cleanup tmp container
-	if err = l.removeContainer(c.ID); err != nil {
+	if err = l.removeContainer(ctx, c.ID); err != nil {
 		return err
 	}
-
-	// save image name
-	l.BenchmarkImage = imageName
+	l.tmp = ""
 
 	return nil
 }
 
+// environment of the image committed after the `before` commands, docker merges
+// the container variables into it, so the `env` ones are reset to their value
+// on the `image` config or blanked, as they may be secrets passed from the host
+func commitEnv(image *container.Config, env []string) []string {
+
+	var base []string
+	if image != nil {
+		base = image.Env
+	}
+
+	committed := append([]string{}, base...)
+	for _, e := range env {
+		name := strings.SplitN(e, "=", 2)[0]
+
+		found := false
+		for _, b := range base {
+			if strings.SplitN(b, "=", 2)[0] == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			committed = append(committed, name+"=")
+		}
+	}
+
+	return committed
+}
+
 // run before commands if specified and create new image
-func (l *LocalBuilder) runBeforeCommands() error {
+func (l *LocalBuilder) runBeforeCommands(ctx context.Context) error {
 
 	if len(l.Before) == 0 {
-		fmt.Printf(" \033[36m no commands to run before !\n\033[m")
+		fmt.Fprintf(l.out(), " \033[36m no commands to run before !\n\033[m")
 		return nil
 	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
-	tmpName := utils.RandString(10)
+	tmpName := resourceName("ben-tmp", l.Labels)
 
 	config := &container.Config{
 		Image:      l.BenchmarkImage,
 		WorkingDir: "/tmp",
 		OpenStdin:  true,
 		Cmd:        l.Before,
+		Env:        l.Env,
+		Labels:     l.Labels,
 	}
 
 	// create tmp container to run `before` commands
-	c, err := l.Client.ContainerCreate(l.Context, config, nil, nil, tmpName)
+	c, err := l.Client.ContainerCreate(ctx, config, nil, nil, tmpName)
 	if err != nil {
-		fmt.Printf("\r  \033[36mrunning before commands \033[m %s ", color.RedString("failed !"))
+		fmt.Fprintf(l.out(), "\r  \033[36mrunning before commands \033[m %s ", color.RedString("failed !"))
 		return errors.Wrap(err, "failed creating container")
 	}
+	l.tmp = c.ID
 
 	s := spinner.New()
 	spin := true
 	go func() {
 		defer wg.Done()
 		for spin == true {
-			time.Sleep(100 * time.Millisecond)
-			fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(l.Before, " "))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			fmt.Fprintf(l.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)", color.MagentaString(s.Next()), strings.Join(l.Before, " "))
 		}
 	}()
 
 	// start tmp container
-	err = l.Client.ContainerStart(l.Context, c.ID, types.ContainerStartOptions{})
+	err = l.Client.ContainerStart(ctx, c.ID, types.ContainerStartOptions{})
 	if err != nil {
 		spin = false
 		wg.Wait()
@@ -325,7 +468,7 @@ func (l *LocalBuilder) runBeforeCommands() error {
 	}
 
 	// wait until container exits
-	exit, errC := l.Client.ContainerWait(l.Context, c.ID)
+	exit, errC := l.Client.ContainerWait(ctx, c.ID)
 	if err := errC; err != nil {
 		spin = false
 		wg.Wait()
@@ -339,27 +482,39 @@ func (l *LocalBuilder) runBeforeCommands() error {
 		spin = false
 		wg.Wait()
 
-		fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(l.Before, " "))
+		fmt.Fprintf(l.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(l.Before, " "))
 
-		l.showOutput(c.ID)
+		l.showOutput(ctx, c.ID)
 
-		if err = l.removeContainer(c.ID); err != nil {
+		if err = l.removeContainer(ctx, c.ID); err != nil {
 			return errors.Wrap(err, "failed removing tmp container")
 		}
+		l.tmp = ""
 
-		_, err := l.Client.ImageRemove(l.Context, l.BenchmarkImage, types.ImageRemoveOptions{})
+		_, err := l.Client.ImageRemove(ctx, l.BenchmarkImage, types.ImageRemoveOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed removing benchmark image")
 		}
+		l.BenchmarkImage = ""
 
 		return errors.New("running 'before' commands failed")
 	}
 
 	oldImage := l.BenchmarkImage
 
-	// create new image
-	imageName := "ben-final-" + strings.ToLower(utils.RandString(4))
-	_, err = l.Client.ContainerCommit(l.Context, c.ID, types.ContainerCommitOptions{Reference: imageName})
+	base, _, err := l.Client.ImageInspectWithRaw(ctx, oldImage)
+	if err != nil {
+		spin = false
+		wg.Wait()
+		return errors.Wrap(err, "failed inspecting benchmark image")
+	}
+
+	// create new image, without the variables of the `before` container
+	imageName := resourceName("ben-final", l.Labels)
+	_, err = l.Client.ContainerCommit(ctx, c.ID, types.ContainerCommitOptions{
+		Reference: imageName,
+		Config:    &container.Config{Env: commitEnv(base.Config, l.Env)},
+	})
 	if err != nil {
 		spin = false
 		wg.Wait()
@@ -370,15 +525,16 @@ func (l *LocalBuilder) runBeforeCommands() error {
 	l.BenchmarkImage = imageName
 
 	// cleanup tmp container
-	if err = l.removeContainer(c.ID); err != nil {
+	if err = l.removeContainer(ctx, c.ID); err != nil {
 		spin = false
 		wg.Wait()
 
 		return err
 	}
+	l.tmp = ""
 
 	// cleanup previous image
-	_, err = l.Client.ImageRemove(l.Context, oldImage, types.ImageRemoveOptions{})
+	_, err = l.Client.ImageRemove(ctx, oldImage, types.ImageRemoveOptions{})
 	if err != nil {
 		spin = false
 		wg.Wait()
@@ -389,17 +545,17 @@ func (l *LocalBuilder) runBeforeCommands() error {
 	spin = false
 	wg.Wait()
 
-	fmt.Printf("\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(l.Before, " "))
+	fmt.Fprintf(l.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(l.Before, " "))
 
 	return nil
 }
 
 // writes container logs to stdout
 // TODO: print better
-func (l *LocalBuilder) showOutput(containerID string) error {
+func (l *LocalBuilder) showOutput(ctx context.Context, containerID string) error {
 
 	// store container stdout
-	reader, err := l.Client.ContainerLogs(l.Context, containerID, types.ContainerLogsOptions{ShowStdout: true,
+	reader, err := l.Client.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{ShowStdout: true,
 		ShowStderr: true})
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch logs")
@@ -408,16 +564,43 @@ func (l *LocalBuilder) showOutput(containerID string) error {
 
 	info, err := ioutil.ReadAll(reader)
 
-	fmt.Println()
-	fmt.Printf(string(info))
+	fmt.Fprintln(l.out())
+	fmt.Fprint(l.out(), string(info))
 
 	return nil
 }
 
-func (l *LocalBuilder) removeContainer(containerID string) error {
-	err := l.Client.ContainerRemove(l.Context, containerID, types.ContainerRemoveOptions{RemoveVolumes: true})
+func (l *LocalBuilder) removeContainer(ctx context.Context, containerID string) error {
+	err := l.Client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{RemoveVolumes: true})
 	if err != nil {
 		return errors.Wrap(err, "failed removing container")
 	}
 	return nil
 }
+
+// machine profile along with its limits, ie: local-2cpu-1g (2 CPU 1GB)
+func (l *LocalBuilder) machine() string {
+	m := l.Machine
+	if m == "" {
+		m = "local"
+	}
+	if !l.Limits.IsZero() {
+		m += " (" + l.Limits.String() + ")"
+	}
+	return m
+}
+
+// api version of the docker client, also served by podman's docker compatible api
+const dockerAPIVersion = "1.25"
+
+// connects to the daemon listening on `host`, or the one set on the environment when blank
+func newDockerClient(host string) (*client.Client, error) {
+	if host == "" {
+		return client.NewEnvClient()
+	}
+	return client.NewClient(host, dockerAPIVersion, nil, nil)
+}
+
+func (l *LocalBuilder) out() io.Writer {
+	return output(l.Output)
+}
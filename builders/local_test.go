@@ -1,19 +1,30 @@
 package builders
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/api/types/container"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestBuilder_commitEnv(t *testing.T) {
+	image := &container.Config{Env: []string{"PATH=/usr/bin", "GOPATH=/go"}}
+
+	env := commitEnv(image, []string{"GOPATH=/src", "NPM_TOKEN=secret"})
+	assert.Equal(t, env, []string{"PATH=/usr/bin", "GOPATH=/go", "NPM_TOKEN="})
+
+	assert.Equal(t, commitEnv(nil, []string{"NPM_TOKEN=secret"}), []string{"NPM_TOKEN="})
+}
+
 func TestBuilder_LocalBuilder_Init(t *testing.T) {
 
 	t.Run("valid", func(t *testing.T) {
 		builder := &LocalBuilder{
 			Image: "golang:1.4",
 		}
-		err := builder.Init()
+		err := builder.Init(context.Background())
 		assert.Nil(t, err)
 		assert.NotNil(t, builder.Client)
 	})
@@ -25,21 +36,21 @@ func TestBuilder_LocalBuilder_PrepareImage(t *testing.T) {
 		builder := &LocalBuilder{
 			Image: "golang:3.123123",
 		}
-		builder.Init()
-		err := builder.PrepareImage()
+		builder.Init(context.Background())
+		err := builder.PrepareImage(context.Background())
 		assert.NotNil(t, err)
 		assert.Equal(t, strings.Contains(err.Error(), "golang:3.123123 not found"), true)
-		builder.Cleanup()
+		builder.Cleanup(context.Background())
 	})
 
 	t.Run("valid", func(t *testing.T) {
 		builder := &LocalBuilder{
 			Image: "golang:1.4",
 		}
-		builder.Init()
-		err := builder.PrepareImage()
+		builder.Init(context.Background())
+		err := builder.PrepareImage(context.Background())
 		assert.Nil(t, err)
-		builder.Cleanup()
+		builder.Cleanup(context.Background())
 	})
 }
 
@@ -50,11 +61,11 @@ func TestBuilder_LocalBuilder_SetupContainer(t *testing.T) {
 			Image:   "golang:2.999",
 			Command: []string{"ls", "-lah"},
 		}
-		builder.Init()
-		err := builder.SetupContainer()
+		builder.Init(context.Background())
+		err := builder.SetupContainer(context.Background())
 		assert.NotNil(t, err)
 		assert.Equal(t, strings.Contains(err.Error(), "benchmark image not prepared"), true)
-		builder.Cleanup()
+		builder.Cleanup(context.Background())
 	})
 
 	t.Run("image exist", func(t *testing.T) {
@@ -62,9 +73,9 @@ func TestBuilder_LocalBuilder_SetupContainer(t *testing.T) {
 			Image:   "golang:1.4",
 			Command: []string{"ls", "-lah"},
 		}
-		builder.Init()
-		builder.PrepareImage()
-		err := builder.SetupContainer()
+		builder.Init(context.Background())
+		builder.PrepareImage(context.Background())
+		err := builder.SetupContainer(context.Background())
 		assert.Nil(t, err)
 	})
 }
@@ -75,9 +86,9 @@ func TestBuilder_LocalBuilder_Report(t *testing.T) {
 		Image:   "golang:1.4",
 		Command: []string{"ls", "-lah"},
 	}
-	builder.Init()
-	builder.PrepareImage()
-	builder.SetupContainer()
+	builder.Init(context.Background())
+	builder.PrepareImage(context.Background())
+	builder.SetupContainer(context.Background())
 	d := builder.Report()
 	assert.NotNil(t, d)
 }
@@ -90,20 +101,34 @@ func TestBuilder_LocalBuilder_Cleanup(t *testing.T) {
 			Image:   "golang:1.4",
 			Command: []string{"ls", "-lah"},
 		}
-		builder.Init()
-		builder.PrepareImage()
-		builder.SetupContainer()
-		err := builder.Cleanup()
+		builder.Init(context.Background())
+		builder.PrepareImage(context.Background())
+		builder.SetupContainer(context.Background())
+		err := builder.Cleanup(context.Background())
 		assert.Nil(t, err)
 	})
 
-	t.Run("failed cleanup", func(t *testing.T) {
+	t.Run("nothing to cleanup", func(t *testing.T) {
 		builder := &LocalBuilder{
 			Image: "golang:1.4",
 		}
 
-		err := builder.Cleanup()
+		err := builder.Cleanup(context.Background())
+		assert.Nil(t, err)
+	})
+
+	t.Run("cleanup after failed setup", func(t *testing.T) {
+		builder := &LocalBuilder{
+			Image: "golang:1.4",
+		}
+		builder.Init(context.Background())
+		builder.PrepareImage(context.Background())
+
+		err := builder.SetupContainer(context.Background())
 		assert.NotNil(t, err)
-		assert.Equal(t, strings.Contains(err.Error(), "container doesn't exist"), true)
+
+		err = builder.Cleanup(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, builder.BenchmarkImage, "")
 	})
 }
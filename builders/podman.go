@@ -0,0 +1,73 @@
+package builders
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	Register(Backend{
+		Prefix: "podman",
+		Validate: func(size string) error {
+			if size != "" {
+				return errors.Errorf("invalid podman size: %s", size)
+			}
+			return nil
+		},
+		Limits: true,
+		Local:  true,
+		New: func(opts Options) (RuntimeBuilder, error) {
+			return &LocalBuilder{
+				Image:   opts.Image,
+				Before:  opts.Before,
+				Command: opts.Command,
+				Env:     opts.Env,
+				Source:  opts.Source,
+				Host:    PodmanHost(),
+				Machine: opts.Machine,
+				Limits:  opts.Limits,
+				Output:  opts.Output,
+				Labels:  opts.Labels,
+			}, nil
+		},
+	})
+}
+
+// PodmanHost returns the podman api socket: CONTAINER_HOST when set to
+// a unix socket, the rootless socket of the user, or the system one
+func PodmanHost() string {
+	if h := os.Getenv("CONTAINER_HOST"); strings.HasPrefix(h, "unix://") {
+		return h
+	}
+
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sock := dir + "/podman/podman.sock"
+		if _, err := os.Stat(sock); err == nil {
+			return "unix://" + sock
+		}
+	}
+
+	sock := fmt.Sprintf("/run/user/%d/podman/podman.sock", os.Getuid())
+	if _, err := os.Stat(sock); err == nil {
+		return "unix://" + sock
+	}
+
+	return "unix:///run/podman/podman.sock"
+}
+
+// NewPodmanSweeper connects to the podman api socket, failing when it's missing
+func NewPodmanSweeper() (*LocalSweeper, error) {
+	host := PodmanHost()
+	if _, err := os.Stat(strings.TrimPrefix(host, "unix://")); err != nil {
+		return nil, errors.Errorf("podman socket not found: %s", host)
+	}
+
+	c, err := newDockerClient(host)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to podman")
+	}
+	return &LocalSweeper{Client: c, Backend: "podman"}, nil
+}
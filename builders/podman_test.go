@@ -0,0 +1,29 @@
+package builders
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodman_PodmanHost(t *testing.T) {
+	os.Setenv("CONTAINER_HOST", "unix:///tmp/podman.sock")
+	defer os.Unsetenv("CONTAINER_HOST")
+
+	assert.Equal(t, PodmanHost(), "unix:///tmp/podman.sock")
+
+	b, err := New(Options{Image: "golang:1.9", Machine: "podman"})
+	assert.Nil(t, err)
+	assert.Equal(t, b.(*LocalBuilder).Host, "unix:///tmp/podman.sock")
+	assert.Equal(t, b.Report().Machine, "podman")
+}
+
+func TestPodman_NewPodmanSweeper(t *testing.T) {
+	os.Setenv("CONTAINER_HOST", "unix:///tmp/ben-missing-podman.sock")
+	defer os.Unsetenv("CONTAINER_HOST")
+
+	_, err := NewPodmanSweeper()
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "podman socket not found: unix:///tmp/ben-missing-podman.sock")
+}
@@ -0,0 +1,155 @@
+package builders
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/drish/ben/utils"
+	"github.com/pkg/errors"
+)
+
+// Options are the environment settings builders are created with
+type Options struct {
+	Runtime string   // runtime name, ie: golang
+	Image   string   // runtime image, ie: golang:1.9
+	Before  []string // commands to run before the benchmark
+	Command []string // benchmark command
+	Env     []string // environment variables, ie: GOMAXPROCS=1
+	Source  string   // project directory copied into the image, default to the working directory
+
+	Machine string // machine name, ie: hyper-s4
+	Size    string // machine name without the backend prefix, ie: s4
+	Limits  Limits // inline resource limits, on backends supporting them
+
+	Options json.RawMessage // backend specific settings, decoded by the backend
+
+	Labels map[string]string // set on every created container and image
+	Output io.Writer         // progress output, default to stdout
+}
+
+// Spec is the part of an environment backends check upfront, before any run
+type Spec struct {
+	Machine string          // machine name, ie: hyper-s4
+	Version string          // runtime version, blank when unset
+	Options json.RawMessage // backend specific settings, nil when unset
+}
+
+// Backend creates the builders of the machines starting with its prefix
+type Backend struct {
+	Prefix string   // machine prefix, ie: hyper matches hyper-s4
+	Sizes  []string // valid sizes, ie: s4, checked by `Validate` when nil
+
+	// validates sizes not known upfront, ie: local-2cpu-1g, blank sizes are
+	// the bare prefix machine and are invalid unless validated
+	Validate func(size string) error
+
+	// backends supporting cpus, memory and cpuset limits
+	Limits bool
+
+	// backends running on the host, which disturb each other when run at once
+	Local bool
+
+	// decodes and validates the backend specific settings, which may be nil,
+	// backends without it don't take settings
+	Options func(raw json.RawMessage) error
+
+	// checks the environment settings the backend depends on, ie: versions
+	// of backends running the installed toolchain, optional
+	Check func(spec Spec) error
+
+	New func(opts Options) (RuntimeBuilder, error)
+}
+
+var (
+	mu sync.RWMutex
+
+	// backends by prefix
+	backends = map[string]Backend{}
+)
+
+// Register adds a backend, replacing the one with the same prefix
+func Register(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	backends[b.Prefix] = b
+}
+
+// Lookup finds the backend of the machine, along with the machine size,
+// the longest prefix wins when several match. Machines are the prefix followed
+// by `-size`, or by `://size` for addresses, ie: ssh://bench@perf-1
+func Lookup(machine string) (Backend, string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var found Backend
+	var size string
+	ok := false
+
+	for prefix, b := range backends {
+		if ok && len(prefix) <= len(found.Prefix) {
+			continue
+		}
+		switch {
+		case machine == prefix:
+			found, size, ok = b, "", true
+		case strings.HasPrefix(machine, prefix+"-"):
+			found, size, ok = b, strings.TrimPrefix(machine, prefix+"-"), true
+		case strings.HasPrefix(machine, prefix+"://"):
+			found, size, ok = b, strings.TrimPrefix(machine, prefix+"://"), true
+		}
+	}
+	return found, size, ok
+}
+
+// ValidateMachine checks the machine belongs to a backend and has a valid size
+func ValidateMachine(machine string) error {
+	b, size, ok := Lookup(machine)
+	if !ok {
+		return errors.Errorf("invalid machine size: %s", machine)
+	}
+
+	if b.Sizes != nil {
+		if !utils.Contains(size, b.Sizes) {
+			return errors.Errorf("invalid machine size: %s", machine)
+		}
+		return nil
+	}
+
+	if b.Validate == nil || b.Validate(size) != nil {
+		return errors.Errorf("invalid machine size: %s", machine)
+	}
+	return nil
+}
+
+// Check validates the environment against the backend of its machine,
+// the machine must be valid
+func Check(spec Spec) error {
+	b, _, _ := Lookup(spec.Machine)
+
+	if b.Options != nil {
+		if err := b.Options(spec.Options); err != nil {
+			return err
+		}
+	} else if len(spec.Options) > 0 && string(spec.Options) != "null" {
+		return errors.Errorf("options aren't supported on %s machines", spec.Machine)
+	}
+
+	if b.Check == nil {
+		return nil
+	}
+	return b.Check(spec)
+}
+
+// New creates the builder of the machine set on the options
+func New(opts Options) (RuntimeBuilder, error) {
+	if err := ValidateMachine(opts.Machine); err != nil {
+		return nil, err
+	}
+
+	b, size, _ := Lookup(opts.Machine)
+	opts.Size = size
+	return b.New(opts)
+}
@@ -0,0 +1,82 @@
+package builders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Lookup(t *testing.T) {
+
+	t.Run("hyper", func(t *testing.T) {
+		b, size, ok := Lookup("hyper-s4")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, b.Prefix, "hyper")
+		assert.Equal(t, size, "s4")
+	})
+
+	t.Run("local", func(t *testing.T) {
+		b, size, ok := Lookup("local")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, b.Local, true)
+		assert.Equal(t, size, "")
+	})
+
+	t.Run("longest prefix", func(t *testing.T) {
+		Register(Backend{Prefix: "local-gpu", Sizes: []string{"t4"}})
+		defer func() {
+			mu.Lock()
+			delete(backends, "local-gpu")
+			mu.Unlock()
+		}()
+
+		b, size, ok := Lookup("local-gpu-t4")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, b.Prefix, "local-gpu")
+		assert.Equal(t, size, "t4")
+	})
+
+	t.Run("address", func(t *testing.T) {
+		b, size, ok := Lookup("ssh://bench@perf-1:2222")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, b.Prefix, "ssh")
+		assert.Equal(t, size, "bench@perf-1:2222")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, _, ok := Lookup("gce-n1")
+		assert.Equal(t, ok, false)
+	})
+}
+
+func TestRegistry_ValidateMachine(t *testing.T) {
+	assert.Nil(t, ValidateMachine("hyper-s4"))
+	assert.Nil(t, ValidateMachine("local-2cpu-1g"))
+	assert.Nil(t, ValidateMachine("local-as-hyper-m1"))
+
+	for _, m := range []string{"hyper", "hyper-s9", "local-fast", "s4", ""} {
+		err := ValidateMachine(m)
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "invalid machine size: "+m)
+	}
+}
+
+func TestRegistry_New(t *testing.T) {
+
+	t.Run("hyper", func(t *testing.T) {
+		b, err := New(Options{Image: "golang:1.9", Machine: "hyper-m2"})
+		assert.Nil(t, err)
+		assert.Equal(t, b.(*HyperBuilder).HyperSize, "m2")
+	})
+
+	t.Run("local limits", func(t *testing.T) {
+		b, err := New(Options{Image: "golang:1.9", Machine: "local-2cpu-1g", Limits: Limits{CPUs: 1}})
+		assert.Nil(t, err)
+		assert.Equal(t, b.(*LocalBuilder).Limits, Limits{CPUs: 1, Memory: 1 << 30})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(Options{Machine: "hyper"})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,625 @@
+package builders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drish/ben/reporter"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHOptions are the `options` of ssh machines, all optional
+type SSHOptions struct {
+	IdentityFile          string // private key file, the ssh agent keys are used when blank
+	Agent                 bool   // offers the ssh agent keys along with the identity file
+	KnownHosts            string // known hosts file the host key is checked against, default to ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool   // skips host key checking, for throwaway hosts only
+	Docker                bool   // runs `before` and the benchmark inside docker on the host, with the runtime image
+	Dir                   string // directory the scratch directory is created in, default to /tmp
+}
+
+// decodes the environment options
+func sshOptions(raw json.RawMessage) (SSHOptions, error) {
+	var o SSHOptions
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &o); err != nil {
+			return o, errors.Wrap(err, "invalid ssh options")
+		}
+	}
+	return o, nil
+}
+
+// parses user@host[:port] into the user and the dialed address, port default to 22
+func parseSSHAddress(addr string) (string, string, error) {
+	u, err := url.Parse("ssh://" + addr)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Hostname() == "" || u.Path != "" || u.RawQuery != "" {
+		return "", "", errors.Errorf("invalid ssh address: %s", addr)
+	}
+
+	// passwords aren't supported, only keys
+	if _, set := u.User.Password(); set {
+		return "", "", errors.Errorf("invalid ssh address: %s", addr)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+	return u.User.Username(), net.JoinHostPort(u.Hostname(), port), nil
+}
+
+func init() {
+	Register(Backend{
+		Prefix: "ssh",
+		Validate: func(size string) error {
+			_, _, err := parseSSHAddress(size)
+			return err
+		},
+		Options: func(raw json.RawMessage) error {
+			_, err := sshOptions(raw)
+			return err
+		},
+		Check: func(spec Spec) error {
+			o, _ := sshOptions(spec.Options)
+			if spec.Version != "" && !o.Docker {
+				return errors.New("ssh machines without docker run the installed toolchain, version can't be set")
+			}
+			return nil
+		},
+		New: func(opts Options) (RuntimeBuilder, error) {
+			user, addr, err := parseSSHAddress(opts.Size)
+			if err != nil {
+				return nil, err
+			}
+
+			settings, err := sshOptions(opts.Options)
+			if err != nil {
+				return nil, err
+			}
+
+			return &SSHBuilder{
+				Runtime:  opts.Runtime,
+				Image:    opts.Image,
+				Before:   opts.Before,
+				Command:  opts.Command,
+				Env:      opts.Env,
+				Source:   opts.Source,
+				Machine:  opts.Machine,
+				User:     user,
+				Addr:     addr,
+				Settings: settings,
+				Output:   opts.Output,
+				Labels:   opts.Labels,
+			}, nil
+		},
+	})
+}
+
+// SSHBuilder streams the project to a remote host over ssh and runs the
+// benchmark there, as processes on a scratch directory or inside docker
+type SSHBuilder struct {
+	Runtime        string            // runtime name, picks the toolchain reported, ie: golang
+	Image          string            // runtime image, when running inside docker
+	Command        []string          // benchmark command
+	Before         []string          // commands to run before bench
+	Env            []string          // environment variables of the commands, ie: GOMAXPROCS=1
+	Source         string            // project directory streamed to the host, default to the working directory
+	Machine        string            // machine name, ie: ssh://bench@perf-1
+	User           string            // remote user
+	Addr           string            // remote host and port, ie: perf-1:22
+	Settings       SSHOptions        // authentication, host key checking and docker
+	Client         *ssh.Client       // ssh connection
+	Dir            string            // remote scratch directory
+	BenchmarkImage string            // remote image holding the project, when running inside docker
+	Results        string            // benchmark output
+	ExitCode       int               // exit code of the benchmark command
+	Info           reporter.HostInfo // reported instead of the docker info, when not running inside docker
+	DockerVersion  string            // remote docker version, when running inside docker
+	Output         io.Writer         // progress output, default to stdout
+	Labels         map[string]string // set on the remote containers and images
+
+	agent   net.Conn // ssh agent connection
+	tmp     string   // temporary remote container, removed on cleanup if left behind
+	bench   string   // benchmark container name, when running inside docker
+	running bool     // benchmark left running, ie: canceled, killed on cleanup
+}
+
+// Init connects to the host and collects its info
+func (s *SSHBuilder) Init(ctx context.Context) error {
+
+	fmt.Fprintf(s.out(), "  \033[36msetting up ssh environment for \033[m%s on %s \n", s.Runtime, s.Addr)
+
+	auth, err := s.auth()
+	if err != nil {
+		return err
+	}
+
+	hostKey, err := s.hostKeyCallback()
+	if err != nil {
+		return err
+	}
+
+	config := &ssh.ClientConfig{
+		User:            s.User,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         30 * time.Second,
+	}
+
+	s.Client, err = ssh.Dial("tcp", s.Addr, config)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to %s", s.Addr)
+	}
+
+	if s.Settings.Docker {
+		s.DockerVersion = s.output(ctx, "docker version --format '{{.Server.Version}}'")
+		return nil
+	}
+
+	s.Info = reporter.HostInfo{
+		OS:     s.output(ctx, `(. /etc/os-release && echo "$PRETTY_NAME") 2>/dev/null || uname -s`),
+		Kernel: s.output(ctx, "uname -sr"),
+		CPU:    s.output(ctx, `grep -m1 "model name" /proc/cpuinfo | cut -d: -f2`),
+		Arch:   s.output(ctx, "uname -m"),
+	}
+	if args, ok := toolchainCommands[s.Runtime]; ok {
+		s.Info.Toolchain = s.output(ctx, shellJoin(args))
+	}
+	return nil
+}
+
+// PrepareImage streams the project into a remote scratch directory and runs
+// `before` commands on it, inside docker the result is committed to an image
+func (s *SSHBuilder) PrepareImage(ctx context.Context) error {
+
+	dir := s.Settings.Dir
+	if dir == "" {
+		dir = "/tmp"
+	}
+
+	var out bytes.Buffer
+	if err := s.run(ctx, "mktemp -d "+shellQuote(strings.TrimSuffix(dir, "/")+"/ben-ssh-XXXXXX"), nil, &out, &out); err != nil {
+		return errors.Wrapf(err, "failed creating scratch directory: %s", strings.TrimSpace(out.String()))
+	}
+	s.Dir = strings.TrimSpace(out.String())
+
+	archive := tarDir(s.Source)
+	defer archive.Close()
+
+	out.Reset()
+	if err := s.run(ctx, "tar -xf - -C "+shellQuote(s.Dir), archive, &out, &out); err != nil {
+		fmt.Fprintf(s.out(), "\r  \033[36mcopying project \033[m %s\n", color.RedString("failed !"))
+		fmt.Fprint(s.out(), out.String())
+		return errors.Wrap(err, "failed copying project")
+	}
+	fmt.Fprintf(s.out(), "\r  \033[36mcopying project \033[m %s\n", color.GreenString("done !"))
+
+	if s.Settings.Docker {
+		return s.prepareDockerImage(ctx)
+	}
+
+	if len(s.Before) == 0 {
+		fmt.Fprintf(s.out(), " \033[36m no commands to run before !\n\033[m")
+		return nil
+	}
+
+	out.Reset()
+	if err := s.run(ctx, s.shell(s.Before), nil, &out, &out); err != nil {
+		fmt.Fprintf(s.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(s.Before, " "))
+		fmt.Fprintln(s.out())
+		fmt.Fprint(s.out(), out.String())
+		return errors.New("running 'before' commands failed")
+	}
+
+	fmt.Fprintf(s.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(s.Before, " "))
+	return nil
+}
+
+// copies the scratch directory into a container of the runtime image, runs
+// `before` commands on it and commits the benchmark image, like local docker
+func (s *SSHBuilder) prepareDockerImage(ctx context.Context) error {
+
+	var out bytes.Buffer
+	if err := s.run(ctx, "docker pull "+shellQuote(s.Image), nil, &out, &out); err != nil {
+		fmt.Fprintf(s.out(), "\r  \033[36mpreparing image \033[m %s\n", color.RedString("failed !"))
+		fmt.Fprint(s.out(), out.String())
+		return errors.Wrap(err, "failed preparing image")
+	}
+	fmt.Fprintf(s.out(), "\r  \033[36mpreparing image \033[m %s\n", color.GreenString("done !"))
+
+	s.tmp = resourceName("ben-tmp", s.Labels)
+
+	out.Reset()
+	create := s.docker("create", "--name", s.tmp) + " " + shellJoin(append([]string{s.Image}, s.Before...))
+	if err := s.run(ctx, create, nil, &out, &out); err != nil {
+		s.tmp = ""
+		return errors.Wrapf(err, "failed creating container: %s", strings.TrimSpace(out.String()))
+	}
+
+	out.Reset()
+	if err := s.run(ctx, shellJoin([]string{"docker", "cp", s.Dir + "/.", s.tmp + ":/tmp"}), nil, &out, &out); err != nil {
+		return errors.Wrapf(err, "failed to copy data into container: %s", strings.TrimSpace(out.String()))
+	}
+
+	if len(s.Before) == 0 {
+		fmt.Fprintf(s.out(), " \033[36m no commands to run before !\n\033[m")
+	} else {
+		out.Reset()
+		if err := s.run(ctx, shellJoin([]string{"docker", "start", "-a", s.tmp}), nil, &out, &out); err != nil {
+			fmt.Fprintf(s.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(s.Before, " "))
+			fmt.Fprintln(s.out())
+			fmt.Fprint(s.out(), out.String())
+			return errors.New("running 'before' commands failed")
+		}
+		fmt.Fprintf(s.out(), "\r  \033[36mrunning 'before' commands \033[m %s (%s)\n", color.GreenString("done !"), strings.Join(s.Before, " "))
+	}
+
+	image := resourceName("ben-final", s.Labels)
+	out.Reset()
+	if err := s.run(ctx, shellJoin([]string{"docker", "commit", s.tmp, image}), nil, &out, &out); err != nil {
+		return errors.Wrapf(err, "failed to create benchmark image: %s", strings.TrimSpace(out.String()))
+	}
+	s.BenchmarkImage = image
+
+	if err := s.run(ctx, shellJoin([]string{"docker", "rm", "-f", s.tmp}), nil, nil, nil); err != nil {
+		return errors.Wrap(err, "failed removing container")
+	}
+	s.tmp = ""
+	return nil
+}
+
+// SetupContainer checks the benchmark is ready to run, containers are created on each run
+func (s *SSHBuilder) SetupContainer(ctx context.Context) error {
+
+	if s.Command == nil {
+		return errors.New("command can not be blank")
+	}
+
+	if s.Dir == "" {
+		return errors.New("scratch directory not prepared")
+	}
+
+	if s.Settings.Docker && s.BenchmarkImage == "" {
+		return errors.New("benchmark image not prepared")
+	}
+	return nil
+}
+
+// Benchmark runs the benchmark command on the host, storing its stdout and exit code
+func (s *SSHBuilder) Benchmark(ctx context.Context) error {
+
+	fmt.Fprintf(s.out(), "\r  \033[36mrunning benchmark \033[m (%s)", strings.Join(s.Command, " "))
+
+	// closing the session doesn't stop remote commands, the benchmark is
+	// started on its own process group, or named container, to be killed
+	cmd := s.shell(append([]string{"setsid", "sh", "-c", `echo $$ > "$0" && exec "$@"`, s.pidFile()}, s.Command...)) + "; exit $?"
+	if s.Settings.Docker {
+		cmd = s.docker("run", "--rm", "--name", s.benchName()) + " " + shellJoin(append([]string{s.BenchmarkImage}, s.Command...))
+	}
+
+	var stdout, stderr bytes.Buffer
+	s.running = true
+	err := s.run(ctx, cmd, nil, &stdout, &stderr)
+	s.Results = stdout.String()
+	s.ExitCode = exitCode(err)
+
+	// exited commands are done, as are their removed containers
+	if _, ok := err.(*ssh.ExitError); err == nil || ok {
+		s.running = false
+	}
+
+	if err != nil {
+		fmt.Fprintf(s.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)\n", color.RedString("failed !"), strings.Join(s.Command, " "))
+		fmt.Fprint(s.out(), stderr.String())
+
+		if _, ok := err.(*ssh.ExitError); ok {
+			return errors.Errorf("benchmark command exited with %d", s.ExitCode)
+		}
+		return errors.Wrap(err, "benchmark command failed")
+	}
+
+	fmt.Fprintf(s.out(), "\r  \033[36mrunning benchmark \033[m %s (%s)", color.GreenString("done !"), strings.Join(s.Command, " "))
+	return nil
+}
+
+// RemoveContainer does nothing, benchmark containers are removed once they exit
+func (s *SSHBuilder) RemoveContainer(ctx context.Context) error {
+	return nil
+}
+
+// Cleanup removes the remote containers, image and scratch directory, then disconnects
+func (s *SSHBuilder) Cleanup(ctx context.Context) error {
+
+	if s.agent != nil {
+		s.agent.Close()
+		s.agent = nil
+	}
+
+	// nothing was created before connecting
+	if s.Client == nil {
+		return nil
+	}
+	defer func() {
+		s.Client.Close()
+		s.Client = nil
+	}()
+
+	var failed error
+
+	if s.running {
+		if err := s.run(ctx, s.stop(), nil, nil, nil); err != nil {
+			failed = errors.Wrap(err, "failed stopping benchmark")
+		}
+		s.running = false
+	}
+
+	if s.tmp != "" {
+		if err := s.run(ctx, shellJoin([]string{"docker", "rm", "-f", s.tmp}), nil, nil, nil); err != nil {
+			if failed == nil {
+				failed = errors.Wrap(err, "failed removing container")
+			}
+		}
+		s.tmp = ""
+	}
+
+	if s.BenchmarkImage != "" {
+		if err := s.run(ctx, shellJoin([]string{"docker", "rmi", s.BenchmarkImage}), nil, nil, nil); err != nil && failed == nil {
+			failed = errors.Wrap(err, "failed removing benchmark image")
+		}
+		s.BenchmarkImage = ""
+	}
+
+	if s.Dir != "" {
+		if err := s.run(ctx, "rm -rf "+shellQuote(s.Dir), nil, nil, nil); err != nil && failed == nil {
+			failed = errors.Wrap(err, "failed removing scratch directory")
+		}
+		s.Dir = ""
+	}
+
+	if failed != nil {
+		return failed
+	}
+
+	fmt.Fprintln(s.out())
+	fmt.Fprintf(s.out(), "  \033[36mcleaning up scratch directory\033[m %s \n", color.GreenString(" done !"))
+	return nil
+}
+
+// Display writes the benchmark output to stdout
+func (s *SSHBuilder) Display() error {
+	fmt.Fprintf(s.out(), "  \033[36mdisplaying results\033[m \n")
+	fmt.Fprintln(s.out(), s.Results)
+	return nil
+}
+
+// Report returns data for being later written to fs
+func (s *SSHBuilder) Report() reporter.ReportData {
+	d := reporter.ReportData{
+		Image:   s.Runtime,
+		Results: s.Results,
+		Machine: s.Machine,
+		Before:  strings.Join(s.Before, " "),
+		Command: strings.Join(s.Command, " "),
+	}
+
+	if s.Settings.Docker {
+		d.Image = s.Image
+		d.Machine += " (docker)"
+		d.V = s.DockerVersion
+		return d
+	}
+
+	info := s.Info
+	d.Host = &info
+	return d
+}
+
+// key file and agent authentication, the agent is used when no key file is set
+func (s *SSHBuilder) auth() ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if s.Settings.IdentityFile != "" {
+		b, err := ioutil.ReadFile(expandHome(s.Settings.IdentityFile))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading identity file")
+		}
+		signer, err := ssh.ParsePrivateKey(b)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed parsing identity file")
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if s.Settings.IdentityFile == "" || s.Settings.Agent {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, errors.New("ssh agent not running, SSH_AUTH_SOCK is blank")
+		}
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to connect to ssh agent")
+		}
+		s.agent = conn
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	return methods, nil
+}
+
+// checks the host key against the known hosts file, unless disabled
+func (s *SSHBuilder) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if s.Settings.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := s.Settings.KnownHosts
+	if path == "" {
+		path = "~/.ssh/known_hosts"
+	}
+
+	callback, err := knownhosts.New(expandHome(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading known hosts")
+	}
+	return callback, nil
+}
+
+// runs the shell command on the host, the session is closed when the context is canceled
+func (s *SSHBuilder) run(ctx context.Context, cmd string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := s.Client.NewSession()
+	if err != nil {
+		return errors.Wrap(err, "failed opening ssh session")
+	}
+	defer session.Close()
+
+	// stdout and stderr are copied concurrently, a shared writer is locked
+	if stdout != nil && stdout == stderr {
+		w := &lockedWriter{w: stdout}
+		stdout, stderr = w, w
+	}
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(cmd)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+
+		// the session is done writing to stdout and stderr once run returns
+		<-done
+		return ctx.Err()
+	}
+}
+
+// writer safe for concurrent use
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// trimmed output of the shell command, blank when it fails
+func (s *SSHBuilder) output(ctx context.Context, cmd string) string {
+	var out bytes.Buffer
+	if err := s.run(ctx, cmd, nil, &out, &out); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// runs `args` on the scratch directory with the environment variables
+func (s *SSHBuilder) shell(args []string) string {
+	cmd := "cd " + shellQuote(s.Dir) + " && "
+	if len(s.Env) > 0 {
+		cmd += "env " + shellJoin(s.Env) + " "
+	}
+	return cmd + shellJoin(args)
+}
+
+// file the benchmark process group id is written to, in the scratch directory
+func (s *SSHBuilder) pidFile() string {
+	return s.Dir + "/.ben-pid"
+}
+
+// name of the benchmark container, unique to the builder
+func (s *SSHBuilder) benchName() string {
+	if s.bench == "" {
+		s.bench = resourceName("ben-bench", s.Labels)
+	}
+	return s.bench
+}
+
+// kills the benchmark left running, containers already gone and processes
+// already exited aren't errors
+func (s *SSHBuilder) stop() string {
+	if s.Settings.Docker {
+		name := shellQuote(s.benchName())
+		return "docker rm -f " + name + " >/dev/null 2>&1 || ! docker container inspect " + name + " >/dev/null 2>&1"
+	}
+	return `kill -KILL -"$(cat ` + shellQuote(s.pidFile()) + `)" 2>/dev/null; true`
+}
+
+// docker subcommand with the labels and environment variables, ie: docker run --rm --label ...
+func (s *SSHBuilder) docker(args ...string) string {
+	var labels []string
+	for k, v := range s.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+
+	args = append([]string{"docker"}, args...)
+	args = append(args, "-w", "/tmp")
+	for _, l := range labels {
+		args = append(args, "--label", l)
+	}
+	for _, e := range s.Env {
+		args = append(args, "-e", e)
+	}
+	return shellJoin(args)
+}
+
+func (s *SSHBuilder) out() io.Writer {
+	return output(s.Output)
+}
+
+// exit code of the remote command, -1 when it didn't exit, ie: connection lost
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if e, ok := err.(*ssh.ExitError); ok {
+		return e.ExitStatus()
+	}
+	return -1
+}
+
+// quotes `arg` for the remote posix shell
+func shellQuote(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(os.Getenv("HOME"), strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/drish/ben"
+	"github.com/drish/ben/bisect"
+	"github.com/drish/ben/config"
+	"github.com/drish/ben/git"
+	"github.com/drish/ben/regression"
+	"github.com/drish/ben/utils"
+	"github.com/pkg/errors"
+)
+
+var bisectUsage = `Usage: ben bisect [options...]
+Options:
+  --good        revision where the benchmark is fast, required
+  --bad         revision where the benchmark is slow. Default is HEAD
+  --benchmark   benchmark name, ie: BenchmarkFib10, required
+  -u            benchmark metric unit. Default is ns/op
+  --threshold   slowdown against the good revision that makes a commit bad. Default is 10%
+  --env         index of the ben.json environment benchmarks run on. Default is the baseline environment
+  -d            display benchmark results to stdout. Default is false.
+`
+
+func bisectCmd(args []string) {
+
+	flags := flag.NewFlagSet("bisect", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, bisectUsage)
+	}
+
+	goodFlag := flags.String("good", "", "REQUIRED good revision")
+	badFlag := flags.String("bad", "HEAD", "OPTIONAL bad revision")
+	benchmarkFlag := flags.String("benchmark", "", "REQUIRED benchmark name")
+	unitFlag := flags.String("u", "ns/op", "OPTIONAL metric unit")
+	thresholdFlag := flags.String("threshold", "10%", "OPTIONAL allowed slowdown")
+	envFlag := flags.Int("env", -1, "OPTIONAL environment index")
+	displayFlag := flags.Bool("d", false, "OPTIONAL display benchmark results to stdout")
+	flags.Parse(args)
+
+	if *goodFlag == "" || *benchmarkFlag == "" {
+		utils.Fatal(errors.New("--good and --benchmark are required"))
+	}
+
+	threshold, err := regression.ParseThreshold(*thresholdFlag)
+	if err != nil {
+		utils.Fatal(err)
+	}
+
+	c, err := config.ReadConfig("ben.json")
+	if err != nil {
+		utils.Fatal(err)
+	}
+
+	env := *envFlag
+	if env < 0 {
+		env = c.Baseline
+	}
+
+	res, err := ben.New(c).Bisect(trap(), ben.BisectOptions{
+		Good:        *goodFlag,
+		Bad:         *badFlag,
+		Benchmark:   *benchmarkFlag,
+		Unit:        *unitFlag,
+		Threshold:   threshold,
+		Environment: env,
+		Display:     *displayFlag,
+	})
+	if err != nil {
+		// the steps measured before the failure are still worth seeing
+		if res.Good.Commit != "" {
+			fmt.Println()
+			bisect.WriteTable(os.Stdout, res, *unitFlag)
+		}
+		utils.Fatal(err)
+	}
+
+	fmt.Println()
+	if err := bisect.WriteTable(os.Stdout, res, *unitFlag); err != nil {
+		utils.Fatal(err)
+	}
+
+	subject, _ := git.Subject(".", res.Culprit)
+	fmt.Printf("\n\r  \033[36mfirst bad commit \033[m%s %s\n\n", res.Culprit, subject)
+}
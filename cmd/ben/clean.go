@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/drish/ben/builders"
+	"github.com/drish/ben/utils"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+var cleanUsage = `Usage: ben clean [options...]
+Options:
+  --dry-run      only lists the leftovers, without removing them
+  --older-than   only removes leftovers older than the duration, ie: 24h
+  --backend      local, podman, hyper or all. Default is all, which is local, podman
+                 and hyper, podman is skipped without its socket and hyper without credentials
+`
+
+func cleanCmd(args []string) {
+
+	flags := flag.NewFlagSet("clean", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, cleanUsage)
+	}
+
+	dryRunFlag := flags.Bool("dry-run", false, "OPTIONAL only list leftovers")
+	olderThanFlag := flags.Duration("older-than", 0, "OPTIONAL minimum leftover age")
+	backendFlag := flags.String("backend", "all", "OPTIONAL backend to clean")
+	flags.Parse(args)
+
+	sweepers, err := sweepers(*backendFlag)
+	if err != nil {
+		utils.Fatal(err)
+	}
+
+	ctx := trap()
+
+	// leftovers are removed by the sweeper that found them
+	var leftovers []builders.Leftover
+	var owners []builders.Sweeper
+	for _, s := range sweepers {
+		found, err := s.Find(ctx)
+		if err != nil {
+			utils.Fatal(err)
+		}
+		for _, l := range builders.OlderThan(found, *olderThanFlag, time.Now()) {
+			leftovers = append(leftovers, l)
+			owners = append(owners, s)
+		}
+	}
+
+	if len(leftovers) == 0 {
+		fmt.Printf("\n\r  no leftovers found\n\n")
+		return
+	}
+
+	fmt.Println()
+	writeLeftovers(leftovers)
+	fmt.Println()
+
+	if *dryRunFlag {
+		fmt.Printf("\r  %d leftovers found, run without --dry-run to remove them\n\n", len(leftovers))
+		return
+	}
+
+	// containers are listed before images, so images are no longer in use when removed
+	var failed error
+	removed := 0
+	for i, l := range leftovers {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := owners[i].Remove(ctx, l); err != nil {
+			fmt.Printf("\r  \033[36mremoving %s \033[m %s %s\n", l.Kind, name(l), color.RedString("failed !"))
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+		removed++
+		fmt.Printf("\r  \033[36mremoving %s \033[m %s %s\n", l.Kind, name(l), color.GreenString("done !"))
+	}
+
+	fmt.Printf("\n\r  removed %d of %d leftovers\n\n", removed, len(leftovers))
+	if failed != nil {
+		utils.Fatal(failed)
+	}
+}
+
+// connects to the backends to clean, when cleaning all podman is skipped
+// without its socket and hyper without credentials
+func sweepers(backend string) ([]builders.Sweeper, error) {
+
+	var sweepers []builders.Sweeper
+
+	if backend == "local" || backend == "all" {
+		s, err := builders.NewLocalSweeper()
+		if err != nil {
+			return nil, err
+		}
+		sweepers = append(sweepers, s)
+	}
+
+	if backend == "podman" || backend == "all" {
+		s, err := builders.NewPodmanSweeper()
+		switch {
+		case err == nil:
+			sweepers = append(sweepers, s)
+		case backend == "all":
+			fmt.Printf("\n\r  %s skipping podman: %s\n", color.YellowString("warning !"), err)
+		default:
+			return nil, err
+		}
+	}
+
+	if backend == "hyper" || backend == "all" {
+		s, err := builders.NewHyperSweeper()
+		switch {
+		case err == nil:
+			sweepers = append(sweepers, s)
+		case backend == "all":
+			fmt.Printf("\n\r  %s skipping hyper.sh: %s\n", color.YellowString("warning !"), err)
+		default:
+			return nil, err
+		}
+	}
+
+	if len(sweepers) == 0 {
+		return nil, errors.Errorf("invalid backend: %s", backend)
+	}
+	return sweepers, nil
+}
+
+func writeLeftovers(leftovers []builders.Leftover) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  backend\tkind\tname\trun\tenvironment\tversion\tcreated")
+	for _, l := range leftovers {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\n", l.Backend, l.Kind, name(l), l.RunID,
+			l.Environment, l.Version, l.Created.Local().Format("2006-01-02 15:04"))
+	}
+	tw.Flush()
+}
+
+// untagged images and unnamed containers are shown by their short id
+func name(l builders.Leftover) string {
+	if l.Name != "" {
+		return l.Name
+	}
+	id := l.ID
+	if len(id) > 19 && id[:7] == "sha256:" {
+		id = id[7:]
+	}
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/drish/ben/history"
+	"github.com/drish/ben/utils"
+	"github.com/pkg/errors"
+)
+
+var historyUsage = `Usage: ben history [options...]
+Options:
+  -b          benchmark name, lists the recorded benchmarks when not set
+  -u          metric unit, ie: ns/op. Default is the first unit recorded for the benchmark
+  --env       only show environments containing the text, ie: golang:1.9
+  --chart     render a bar chart instead of a table
+  --history   history directory. Default is ./.ben/history
+`
+
+func historyCmd(args []string) {
+
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, historyUsage)
+	}
+
+	benchmarkFlag := flags.String("b", "", "OPTIONAL benchmark name")
+	unitFlag := flags.String("u", "", "OPTIONAL metric unit")
+	envFlag := flags.String("env", "", "OPTIONAL environment filter")
+	chartFlag := flags.Bool("chart", false, "OPTIONAL render a bar chart")
+	dirFlag := flags.String("history", history.DefaultDir, "OPTIONAL history directory")
+	flags.Parse(args)
+
+	// read only, running it outside a project must not create the directory
+	store, err := history.OpenReadOnly(*dirFlag)
+	if err == history.ErrNoHistory {
+		fmt.Printf("\n\r  no runs recorded on %s\n\n", *dirFlag)
+		return
+	}
+	if err != nil {
+		utils.Fatal(err)
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		utils.Fatal(err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("\n\r  no runs recorded on %s\n\n", *dirFlag)
+		return
+	}
+
+	if *benchmarkFlag == "" {
+		fmt.Printf("\n\r  %d runs recorded, benchmarks:\n\n", len(entries))
+		for _, b := range history.Benchmarks(entries) {
+			fmt.Printf("  %s\n", b)
+		}
+		fmt.Println()
+		return
+	}
+
+	var series []history.Series
+	for _, s := range history.Trend(entries, *benchmarkFlag, *unitFlag) {
+		if strings.Contains(s.Environment, *envFlag) {
+			series = append(series, s)
+		}
+	}
+
+	if len(series) == 0 {
+		utils.Fatal(errors.Errorf("no results recorded for %s", *benchmarkFlag))
+	}
+
+	fmt.Println()
+	if *chartFlag {
+		err = history.WriteChart(os.Stdout, series)
+	} else {
+		err = history.WriteTable(os.Stdout, series)
+	}
+	if err != nil {
+		utils.Fatal(err)
+	}
+}
@@ -1,66 +1,82 @@
 package main
 
 import (
-	"flag"
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
-	"github.com/drish/ben"
-	"github.com/drish/ben/config"
-	"github.com/drish/ben/utils"
+	"github.com/fatih/color"
 )
 
-const (
-	Version = "0.2.0"
-)
-
-var usage = `Usage: ben [options...]
+var usage = `Usage: ben [command] [options...]
+Commands:
+  run         runs the benchmarks defined on ben.json, the default command
+  history     shows a benchmark values over the recorded runs
+  bisect      finds the commit that made a benchmark slower
+  clean       removes containers and images left behind by failed runs
 Options:
-  -o  output file. Default is ./benchmarks.md
-  -d  display benchmark results to stdout. Default is false.
-  -v  prints current version
+  -v          prints current version
+
+Run 'ben <command> -h' to see the command options.
 `
 
-var defaultBenchmarkFile = "./benchmarks.md"
+// repeatable string flag
+type stringsFlag []string
 
-func main() {
-	trap()
-
-	flag.Usage = func() {
-		fmt.Fprint(os.Stderr, fmt.Sprintf(usage))
-	}
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
 
-	outputFlag := flag.String("o", defaultBenchmarkFile, "OPTIONAL output summary file")
-	displayFlag := flag.Bool("d", false, "OPTIONAL display benchmark results to stdout")
-	vFlag := flag.Bool("v", false, "prints current version")
-	flag.Parse()
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
 
-	if *vFlag {
-		fmt.Printf("\n\r  Ben version %s\n\n", Version)
-		os.Exit(0)
-	}
+func main() {
 
-	c, err := config.ReadConfig("ben.json")
-	if err != nil {
-		utils.Fatal(err)
+	args := os.Args[1:]
+	cmd := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
 	}
 
-	err = ben.New(c).Run(*outputFlag, *displayFlag)
-	if err != nil {
-		utils.Fatal(err)
+	switch cmd {
+	case "run":
+		runCmd(args)
+	case "history":
+		historyCmd(args)
+	case "bisect":
+		bisectCmd(args)
+	case "clean":
+		cleanCmd(args)
+	case "help":
+		fmt.Fprint(os.Stderr, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", cmd, usage)
+		os.Exit(2)
 	}
 }
 
-// trappy
-func trap() {
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT)
+// trappy, the first interrupt cancels the returned context so benchmarks
+// are cleaned up and partial results written, the second one force-exits
+func trap() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
+		<-sigs
+		cancel()
+		fmt.Printf("\n\n\r  %s cleaning up, press Ctrl-C again to force exit\n\n", color.YellowString("interrupted !"))
+
 		<-sigs
 		fmt.Println()
 		os.Exit(1)
 	}()
+
+	return ctx
 }
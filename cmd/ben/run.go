@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/drish/ben"
+	"github.com/drish/ben/config"
+	"github.com/drish/ben/history"
+	"github.com/drish/ben/regression"
+	"github.com/drish/ben/reporter"
+	"github.com/drish/ben/utils"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+var runUsage = `Usage: ben [run] [options...]
+Options:
+  -o                output file, can be repeated. Default is ./benchmarks.md
+                    the format is picked from the extension or set as [format:]file, ie: csv:results.txt
+  --format          output format of files without a format prefix, markdown, json, csv, html or template.
+  --template        text/template file used by template outputs.
+  --history         history directory runs are recorded on. Default is ./.ben/history
+  --no-history      don't record the run on the history.
+  --baseline        json report to check the run for regressions against, exits with 3 on regressions.
+  --threshold       allowed slowdown against the baseline, ie: 5%. Default is the ben.json threshold or 5%
+  --revs            comma separated git revisions to benchmark, ie: main,HEAD
+  --parallel        number of environments benchmarked at once. Default is 1
+                    local environments still run one at a time unless --parallel-local is set
+  --parallel-local  allows local environments to run at once
+  --keep-going      keeps benchmarking when an environment fails, reporting its failure
+  -d                display benchmark results to stdout. Default is false.
+  -v                prints current version
+`
+
+var defaultBenchmarkFile = "./benchmarks.md"
+
+// exit code of runs that regressed against the baseline, or miss some of its benchmarks
+const exitRegression = 3
+
+func runCmd(args []string) {
+
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, runUsage)
+	}
+
+	var outputFlag stringsFlag
+	flags.Var(&outputFlag, "o", "OPTIONAL output summary file, can be repeated")
+	formatFlag := flags.String("format", "", "OPTIONAL output format")
+	templateFlag := flags.String("template", "", "OPTIONAL text/template file for template outputs")
+	historyFlag := flags.String("history", history.DefaultDir, "OPTIONAL history directory")
+	noHistoryFlag := flags.Bool("no-history", false, "OPTIONAL don't record the run on the history")
+	baselineFlag := flags.String("baseline", "", "OPTIONAL json report to check for regressions against")
+	thresholdFlag := flags.String("threshold", "", "OPTIONAL allowed slowdown against the baseline")
+	revsFlag := flags.String("revs", "", "OPTIONAL comma separated git revisions to benchmark")
+	parallelFlag := flags.Int("parallel", 1, "OPTIONAL number of environments benchmarked at once")
+	parallelLocalFlag := flags.Bool("parallel-local", false, "OPTIONAL allows local environments to run at once")
+	keepGoingFlag := flags.Bool("keep-going", false, "OPTIONAL keeps benchmarking when an environment fails")
+	displayFlag := flags.Bool("d", false, "OPTIONAL display benchmark results to stdout")
+	vFlag := flags.Bool("v", false, "prints current version")
+	flags.Parse(args)
+
+	if *vFlag {
+		fmt.Printf("\n\r  Ben version %s\n\n", ben.Version)
+		os.Exit(0)
+	}
+
+	if len(outputFlag) == 0 {
+		outputFlag = stringsFlag{defaultBenchmarkFile}
+	}
+
+	// reporters are created upfront so invalid outputs fail before benchmarking
+	var reporters []reporter.Reporter
+	for _, o := range outputFlag {
+		file, format := reporter.ParseOutput(o)
+		if format == "" {
+			format = *formatFlag
+		}
+
+		rep, err := reporter.New(file, format, *templateFlag)
+		if err != nil {
+			utils.Fatal(err)
+		}
+		reporters = append(reporters, rep)
+	}
+
+	historyDir := *historyFlag
+	if *noHistoryFlag {
+		historyDir = ""
+	}
+
+	var baseline *reporter.Report
+	if *baselineFlag != "" {
+		rep, err := reporter.ReadReport(*baselineFlag)
+		if err != nil {
+			utils.Fatal(err)
+		}
+		baseline = &rep
+	}
+
+	if *thresholdFlag != "" {
+		if _, err := regression.ParseThreshold(*thresholdFlag); err != nil {
+			utils.Fatal(err)
+		}
+	}
+
+	var revisions []string
+	if *revsFlag != "" {
+		revisions = strings.Split(*revsFlag, ",")
+	}
+
+	c, err := config.ReadConfig("ben.json")
+	if err != nil {
+		utils.Fatal(err)
+	}
+
+	err = ben.New(c).Run(trap(), ben.Options{
+		Reporters:  reporters,
+		Display:    *displayFlag,
+		HistoryDir: historyDir,
+		Baseline:   baseline,
+		Threshold:  *thresholdFlag,
+		Revisions:  revisions,
+
+		Parallel:      *parallelFlag,
+		ParallelLocal: *parallelLocalFlag,
+		KeepGoing:     *keepGoingFlag,
+	})
+	if cause := errors.Cause(err); cause == regression.ErrRegression || cause == regression.ErrMissing {
+		fmt.Fprintf(os.Stderr, "\n     %s %s\n\n", color.RedString("Error:"), err)
+		os.Exit(exitRegression)
+	}
+	if err != nil {
+		utils.Fatal(err)
+	}
+}
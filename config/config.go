@@ -1,9 +1,17 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"regexp"
+	"strings"
 
+	"github.com/drish/ben/builders"
+	"github.com/drish/ben/parsers"
+	"github.com/drish/ben/regression"
 	"github.com/drish/ben/utils"
 	"github.com/pkg/errors"
 )
@@ -14,43 +22,120 @@ var defaultCommands = map[string]string{
 	"golang": "go test -bench=.",
 }
 
-// machine sizes
-var machineSizes = []string{
-
-	// hyper cloud
-	"hyper-s1", // 1 CPU  64MB
-	"hyper-s2", // 1 CPU  128MB
-	"hyper-s3", // 1 CPU  256MB
-	"hyper-s4", // 1 CPU  512MB
-	"hyper-m1", // 1 CPU  1GB
-	"hyper-m2", // 2 CPUs 2GB
-	"hyper-m3", // 2 CPUs 4GB
-	"hyper-l1",
-	"hyper-l2",
-	"hyper-l3",
-
-	// local docker
-	"local",
+// Metric is a user defined regular expression for extracting results
+// from the benchmark output, ie: `latency: (?P<value>[\d.]+) (?P<unit>\w+)`
+type Metric struct {
+	Name   string // benchmark name, used when the expression has no `name` group
+	Regexp string // regular expression with a `value` group and optional `name` and `unit` groups
+	Unit   string // unit, used when the expression has no `unit` group
+	Better string // "lower" (default) or "higher"
 }
 
 // representation of json config file
 type Environment struct {
-	Machine string   // hyper.sh machine size, ie: s1
-	Version string   // runtime version, ie 1.9
-	Runtime string   // runtime name, ie: golang, ruby, jruby
-	Command string   // benchmark command
-	Before  []string // commands to run on container before benchmark
+	Machine string            // <backend>-<size> or <backend>://<addr>, ie: local, hyper-s4, ssh://bench@perf-1
+	Version string            // runtime version, ie 1.9
+	Runtime string            // runtime name, ie: golang, ruby, jruby
+	Command string            // benchmark command
+	Before  []string          // commands to run on container before benchmark
+	Parser  string            // benchmark output parser, defaults to the runtime's parser
+	Metrics []Metric          // user defined metrics, takes precedence over `parser`
+	Env     map[string]string // environment variables, ie: {"GOMAXPROCS": "1"}
+	PassEnv []string          // host environment variables copied into the containers, ie: ["NODE_OPTIONS"]
+
+	// local benchmark container limits, take precedence over the machine profile ones
+	CPUs   float64 // number of cpus, ie: 1.5
+	Memory string  // memory limit, ie: 512m, 1g
+	Cpuset string  // cpus the container runs on, ie: 0-1
+
+	// backend specific settings, ie: the registry of k8s machines or the identity file of ssh ones
+	Options json.RawMessage
+
+	Repetitions int // number of measured benchmark runs, default to 1
+	Warmup      int // number of discarded runs before the measured ones
 }
 
 type Config struct {
 	Environments []Environment `json:"environments"`
+	Baseline     int           `json:"baseline"` // index of the environment others are compared against
+
+	// expanded into environments appended after the listed ones
+	Matrix *Matrix `json:"matrix"`
+
+	// git revisions every environment is benchmarked on, ie: ["main", "HEAD"]
+	Revisions []string `json:"revisions"`
+
+	// allowed slowdown against a baseline report, ie: "5%"
+	Threshold  string            `json:"threshold"`
+	Thresholds map[string]string `json:"thresholds"` // per benchmark name
+
+	origins []string // matrix entries producing the environments, blank for listed ones
 }
 
-// checks if provided machine size is on list of supported sizes
+// checks if provided machine sizes belong to a registered backend
 func validateMachineSizes(sizes []string) error {
 	for _, s := range sizes {
-		if !utils.Contains(s, machineSizes) {
-			return errors.Errorf("invalid machine size: %s", s)
+		if err := builders.ValidateMachine(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checks inline limits are set on local machines only and parse
+func validateLimits(env Environment) error {
+	if env.CPUs == 0 && env.Memory == "" && env.Cpuset == "" {
+		return nil
+	}
+
+	if b, _, _ := builders.Lookup(env.Machine); !b.Limits {
+		return errors.Errorf("cpus, memory and cpuset are only supported on local machines, not %s", env.Machine)
+	}
+
+	if env.CPUs < 0 {
+		return errors.New("cpus can't be negative")
+	}
+
+	if env.Memory != "" {
+		if _, err := builders.ParseMemory(env.Memory); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checks if user defined metrics compile and are complete
+func validateMetrics(metrics []Metric) error {
+	for i, m := range metrics {
+		re, err := regexp.Compile(m.Regexp)
+		if err != nil {
+			return errors.Wrapf(err, "metric %d invalid regexp", i)
+		}
+
+		if !utils.Contains("value", re.SubexpNames()) {
+			return errors.Errorf("metric %d regexp is missing a `value` group", i)
+		}
+
+		if m.Name == "" && !utils.Contains("name", re.SubexpNames()) {
+			return errors.Errorf("metric %d needs a name or a `name` group", i)
+		}
+
+		if m.Better != "" && m.Better != "lower" && m.Better != "higher" {
+			return errors.Errorf("metric %d invalid better: %s", i, m.Better)
+		}
+	}
+	return nil
+}
+
+// checks environment variable names are usable, ie: no blanks nor `=`
+func validateEnv(env map[string]string, pass []string) error {
+	names := append([]string{}, pass...)
+	for k := range env {
+		names = append(names, k)
+	}
+	for _, n := range names {
+		if n == "" || strings.ContainsAny(n, "= ") {
+			return errors.Errorf("invalid environment variable name: %q", n)
 		}
 	}
 	return nil
@@ -62,22 +147,138 @@ func (c *Config) Validate() error {
 	// validates runtimes
 	for i, env := range c.Environments {
 		if env.Runtime == "" {
-			return errors.Errorf("environment %d runtime can't be blank", i)
+			return errors.Errorf("%s runtime can't be blank", c.name(i))
+		}
+	}
+
+	// validates baseline environment
+	if len(c.Environments) > 0 && (c.Baseline < 0 || c.Baseline >= len(c.Environments)) {
+		return errors.Errorf("baseline %d is not a valid environment index", c.Baseline)
+	}
+
+	// validates repetitions
+	for i, env := range c.Environments {
+		if env.Repetitions < 0 || env.Warmup < 0 {
+			return errors.Errorf("%s repetitions and warmup can't be negative", c.name(i))
+		}
+	}
+
+	// validates parsers
+	for i, env := range c.Environments {
+		if env.Parser == "" {
+			continue
+		}
+		if _, ok := parsers.Lookup(env.Parser); !ok {
+			return errors.Errorf("%s invalid parser: %s", c.name(i), env.Parser)
+		}
+	}
+
+	// validates user defined metrics
+	for i, env := range c.Environments {
+		if err := validateMetrics(env.Metrics); err != nil {
+			return errors.Wrap(err, c.name(i))
+		}
+	}
+
+	// validates environment variables
+	for i, env := range c.Environments {
+		if err := validateEnv(env.Env, env.PassEnv); err != nil {
+			return errors.Wrap(err, c.name(i))
+		}
+	}
+
+	// validates revisions
+	for i, rev := range c.Revisions {
+		if rev == "" {
+			return errors.Errorf("revision %d can't be blank", i)
+		}
+	}
+
+	// validates regression thresholds
+	if c.Threshold != "" {
+		if _, err := regression.ParseThreshold(c.Threshold); err != nil {
+			return err
+		}
+	}
+	for name, t := range c.Thresholds {
+		if _, err := regression.ParseThreshold(t); err != nil {
+			return errors.Wrapf(err, "benchmark %s", name)
 		}
 	}
 
 	// validates machine sizes
-	var sizes []string
-	for _, env := range c.Environments {
-		sizes = append(sizes, env.Machine)
+	for i, env := range c.Environments {
+		if err := validateMachineSizes([]string{env.Machine}); err != nil {
+			if c.origin(i) != "" {
+				return errors.Wrap(err, c.name(i))
+			}
+			return err
+		}
+	}
+
+	// validates local limits, once machines are known to be valid
+	for i, env := range c.Environments {
+		if err := validateLimits(env); err != nil {
+			return errors.Wrap(err, c.name(i))
+		}
 	}
-	if err := validateMachineSizes(sizes); err != nil {
+
+	// validates the settings backends depend on
+	for i, env := range c.Environments {
+		if err := builders.Check(builders.Spec{Machine: env.Machine, Version: env.Version, Options: env.Options}); err != nil {
+			return errors.Wrap(err, c.name(i))
+		}
+	}
+
+	return nil
+}
+
+// expands the matrix into environments, appended after the listed ones
+func (c *Config) expand() error {
+	if c.Matrix == nil {
+		return nil
+	}
+
+	envs, err := c.Matrix.expand()
+	if err != nil {
 		return err
 	}
 
+	c.origins = make([]string, len(c.Environments))
+	for _, e := range envs {
+		c.Environments = append(c.Environments, e.env)
+		c.origins = append(c.origins, e.origin)
+	}
+
+	if len(c.Environments) == 0 {
+		return errors.New("matrix expands to no environments")
+	}
 	return nil
 }
 
+// matrix entry producing the environment `i`, blank for listed environments
+func (c *Config) origin(i int) string {
+	if i < len(c.origins) {
+		return c.origins[i]
+	}
+	return ""
+}
+
+// names the environment `i` on validation errors, pointing at its matrix entry
+func (c *Config) name(i int) string {
+	if o := c.origin(i); o != "" {
+		return fmt.Sprintf("environment %d (%s)", i, o)
+	}
+	return fmt.Sprintf("environment %d", i)
+}
+
+// Hash returns a short hash identifying the configuration
+func (c *Config) Hash() string {
+	b, _ := json.Marshal(c)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
 // parses json bytes into a Config struct
 func ParseConfig(b []byte) (*Config, error) {
 	c := &Config{}
@@ -86,6 +287,10 @@ func ParseConfig(b []byte) (*Config, error) {
 		return nil, errors.Wrap(err, "unmarshalling error")
 	}
 
+	if err := c.expand(); err != nil {
+		return nil, errors.Wrap(err, "matrix error")
+	}
+
 	if err := c.Validate(); err != nil {
 		return nil, errors.Wrap(err, "validation error")
 	}
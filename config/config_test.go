@@ -1,6 +1,8 @@
 package config
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -64,6 +66,344 @@ func TestConfig_Machines(t *testing.T) {
 	})
 }
 
+func TestConfig_Limits(t *testing.T) {
+
+	t.Run("profile", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "local-2cpu-1g"}},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "local", CPUs: 1.5, Memory: "512m", Cpuset: "0-1"}},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid memory", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "local", Memory: "lots"}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: invalid memory: lots")
+	})
+
+	t.Run("not local", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "hyper-s4", CPUs: 2}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: cpus, memory and cpuset are only supported on local machines, not hyper-s4")
+	})
+}
+
+func TestConfig_Kubernetes(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{
+				Runtime: "golang",
+				Machine: "k8s-highcpu",
+				Options: json.RawMessage(`{"registry": "gcr.io/bench", "requests": {"cpu": "2", "memory": "4Gi"}}`),
+			}},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("missing registry", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "k8s-highcpu"}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: k8s machines need a registry option")
+	})
+
+	t.Run("invalid quantity", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{
+				Runtime: "golang",
+				Machine: "k8s-highcpu",
+				Options: json.RawMessage(`{"registry": "gcr.io/bench", "limits": {"memory": "lots"}}`),
+			}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: invalid limits: memory: lots")
+	})
+
+	t.Run("not k8s", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "local", Options: json.RawMessage(`{"registry": "gcr.io/bench"}`)}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: options aren't supported on local machines")
+	})
+}
+
+func TestConfig_Host(t *testing.T) {
+	c := Config{
+		Environments: []Environment{{Runtime: "golang", Version: "1.9", Machine: "host"}},
+	}
+	err := c.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "environment 0: host machines run the installed toolchain, version can't be set")
+}
+
+func TestConfig_SSH(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "ssh://bench@perf-1:2222", Options: json.RawMessage(`{"docker": true}`)}},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "ssh://perf-1"}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "invalid machine size: ssh://perf-1")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{Runtime: "golang", Machine: "ssh://bench@perf-1", Options: json.RawMessage(`{"docker": "yes"}`)}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, strings.HasPrefix(err.Error(), "environment 0: invalid ssh options: "), true)
+	})
+}
+
+func TestConfig_Parser(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		e := Environment{
+			Runtime: "ruby",
+			Machine: "local",
+			Parser:  "benchmark-ips",
+		}
+		c := Config{
+			Environments: []Environment{e},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		e := Environment{
+			Runtime: "ruby",
+			Machine: "local",
+			Parser:  "minitest",
+		}
+		c := Config{
+			Environments: []Environment{e},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0 invalid parser: minitest")
+	})
+}
+
+func TestConfig_Metrics(t *testing.T) {
+
+	validate := func(m Metric) error {
+		e := Environment{
+			Runtime: "node",
+			Machine: "local",
+			Metrics: []Metric{m},
+		}
+		c := Config{
+			Environments: []Environment{e},
+		}
+		return c.Validate()
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		err := validate(Metric{Regexp: `(?P<name>\w+): (?P<value>[\d.]+) (?P<unit>\w+)`, Better: "higher"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		err := validate(Metric{Name: "latency", Regexp: `latency: (?P<value>[\d.]+`})
+		assert.NotNil(t, err)
+		assert.Equal(t, strings.HasPrefix(err.Error(), "environment 0: metric 0 invalid regexp"), true)
+	})
+
+	t.Run("missing value group", func(t *testing.T) {
+		err := validate(Metric{Name: "latency", Regexp: `latency: ([\d.]+)`})
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: metric 0 regexp is missing a `value` group")
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		err := validate(Metric{Regexp: `latency: (?P<value>[\d.]+)`})
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: metric 0 needs a name or a `name` group")
+	})
+
+	t.Run("invalid better", func(t *testing.T) {
+		err := validate(Metric{Name: "latency", Regexp: `latency: (?P<value>[\d.]+)`, Better: "faster"})
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0: metric 0 invalid better: faster")
+	})
+}
+
+func TestConfig_Repetitions(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		e := Environment{
+			Runtime:     "golang",
+			Machine:     "local",
+			Repetitions: 10,
+			Warmup:      2,
+		}
+		c := Config{
+			Environments: []Environment{e},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		e := Environment{
+			Runtime: "golang",
+			Machine: "local",
+			Warmup:  -1,
+		}
+		c := Config{
+			Environments: []Environment{e},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "environment 0 repetitions and warmup can't be negative")
+	})
+}
+
+func TestConfig_Baseline(t *testing.T) {
+
+	envs := []Environment{
+		{Runtime: "golang", Version: "1.8", Machine: "local"},
+		{Runtime: "golang", Version: "1.9", Machine: "local"},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		c := Config{
+			Environments: envs,
+			Baseline:     1,
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		c := Config{
+			Environments: envs,
+			Baseline:     2,
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "baseline 2 is not a valid environment index")
+	})
+}
+
+func TestConfig_Thresholds(t *testing.T) {
+
+	envs := []Environment{
+		{Runtime: "golang", Version: "1.9", Machine: "local"},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		c := Config{
+			Environments: envs,
+			Threshold:    "5%",
+			Thresholds:   map[string]string{"BenchmarkFib10": "10%"},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		c := Config{
+			Environments: envs,
+			Threshold:    "five",
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "invalid threshold: five")
+	})
+
+	t.Run("invalid benchmark threshold", func(t *testing.T) {
+		c := Config{
+			Environments: envs,
+			Thresholds:   map[string]string{"BenchmarkFib10": "-1%"},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "benchmark BenchmarkFib10: threshold can't be negative: -1%")
+	})
+}
+
+func TestConfig_Env(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{
+				Runtime: "golang",
+				Machine: "local",
+				Env:     map[string]string{"GOMAXPROCS": "1"},
+				PassEnv: []string{"GOFLAGS"},
+			}},
+		}
+		err := c.Validate()
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		c := Config{
+			Environments: []Environment{{
+				Runtime: "golang",
+				Machine: "local",
+				PassEnv: []string{"GOFLAGS=-v"},
+			}},
+		}
+		err := c.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), `environment 0: invalid environment variable name: "GOFLAGS=-v"`)
+	})
+}
+
+func TestConfig_Revisions(t *testing.T) {
+	c := Config{
+		Environments: []Environment{{Runtime: "golang", Version: "1.9", Machine: "local"}},
+		Revisions:    []string{"main", ""},
+	}
+	err := c.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "revision 1 can't be blank")
+}
+
+func TestConfig_Hash(t *testing.T) {
+	a := &Config{Environments: []Environment{{Runtime: "golang", Version: "1.9"}}}
+	b := &Config{Environments: []Environment{{Runtime: "golang", Version: "1.8"}}}
+
+	assert.Equal(t, len(a.Hash()), 12)
+	assert.Equal(t, a.Hash(), a.Hash())
+	assert.NotEqual(t, a.Hash(), b.Hash())
+}
+
 func TestConfig_DefaultCommand(t *testing.T) {
 	command := DefaultCommand("golang")
 	assert.Equal(t, command, "go test -bench=.")
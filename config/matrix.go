@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Matrix describes environments as the cartesian product of
+// runtimes, versions, machines and environment variable sets
+type Matrix struct {
+	Runtimes []string            `json:"runtimes"`
+	Versions []string            `json:"versions"`
+	Machines []string            `json:"machines"`
+	Env      []map[string]string `json:"env"`
+
+	// settings shared by every environment of the matrix, ie: command, before
+	Defaults Environment `json:"defaults"`
+
+	// combinations left out of the product, and extra environments
+	// added after it, include entries are applied over the defaults
+	Exclude []MatrixEntry     `json:"exclude"`
+	Include []json.RawMessage `json:"include"`
+}
+
+// MatrixEntry matches the matrix combinations having every field it sets
+type MatrixEntry struct {
+	Runtime string
+	Version string
+	Machine string
+	Env     map[string]string
+}
+
+// matches reports whether every field set on the entry equals the environment one
+func (m MatrixEntry) matches(env Environment) bool {
+	if m.Runtime != "" && m.Runtime != env.Runtime {
+		return false
+	}
+	if m.Version != "" && m.Version != env.Version {
+		return false
+	}
+	if m.Machine != "" && m.Machine != env.Machine {
+		return false
+	}
+	for k, v := range m.Env {
+		if value, ok := env.Env[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// expanded matrix environment, along with a description of the entry producing it
+type matrixEnvironment struct {
+	env    Environment
+	origin string
+}
+
+// expands the matrix, combinations are ordered by runtime, version, machine then env set
+func (m *Matrix) expand() ([]matrixEnvironment, error) {
+
+	var envs []matrixEnvironment
+	excluded := make([]bool, len(m.Exclude))
+
+	// blank dimensions keep the defaults
+	runtimes := orDefault(m.Runtimes, m.Defaults.Runtime)
+	versions := orDefault(m.Versions, m.Defaults.Version)
+	machines := orDefault(m.Machines, m.Defaults.Machine)
+	sets := m.Env
+	if len(sets) == 0 {
+		sets = []map[string]string{nil}
+	}
+
+	for _, runtime := range runtimes {
+		for _, version := range versions {
+			for _, machine := range machines {
+				for i, set := range sets {
+
+					env, err := m.defaults()
+					if err != nil {
+						return nil, err
+					}
+					env.Runtime = runtime
+					env.Version = version
+					env.Machine = machine
+					env.Env = mergeEnv(env.Env, set)
+
+					skip := false
+					for j, ex := range m.Exclude {
+						if ex.matches(env) {
+							excluded[j] = true
+							skip = true
+						}
+					}
+					if skip {
+						continue
+					}
+
+					origin := fmt.Sprintf("matrix runtime=%s version=%s machine=%s", runtime, version, machine)
+					if len(m.Env) > 0 {
+						origin += fmt.Sprintf(" env=%d", i)
+					}
+					envs = append(envs, matrixEnvironment{env: env, origin: origin})
+				}
+			}
+		}
+	}
+
+	// excludes matching nothing are likely typos
+	for j, ok := range excluded {
+		if !ok {
+			return nil, errors.Errorf("matrix exclude %d matches no environment", j)
+		}
+	}
+
+	for i, raw := range m.Include {
+		env, err := m.defaults()
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return nil, errors.Wrapf(err, "matrix include %d", i)
+		}
+		envs = append(envs, matrixEnvironment{env: env, origin: fmt.Sprintf("matrix include %d", i)})
+	}
+
+	return envs, nil
+}
+
+// returns a deep copy of the defaults, so environments don't share slices and maps
+func (m *Matrix) defaults() (Environment, error) {
+	var env Environment
+
+	b, err := json.Marshal(m.Defaults)
+	if err != nil {
+		return env, errors.Wrap(err, "matrix defaults")
+	}
+	if err := json.Unmarshal(b, &env); err != nil {
+		return env, errors.Wrap(err, "matrix defaults")
+	}
+	return env, nil
+}
+
+func orDefault(values []string, def string) []string {
+	if len(values) == 0 {
+		return []string{def}
+	}
+	return values
+}
+
+// returns a copy of `env` with the `set` variables added
+func mergeEnv(env, set map[string]string) map[string]string {
+	if len(env) == 0 && len(set) == 0 {
+		return nil
+	}
+	merged := map[string]string{}
+	for k, v := range env {
+		merged[k] = v
+	}
+	for k, v := range set {
+		merged[k] = v
+	}
+	return merged
+}
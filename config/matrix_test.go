@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrix_Expand(t *testing.T) {
+
+	t.Run("cartesian product", func(t *testing.T) {
+		c, err := ParseConfig([]byte(`{
+			"matrix": {
+				"runtimes": ["node"],
+				"versions": ["8", "9"],
+				"machines": ["local", "hyper-s4"],
+				"env": [{}, {"NODE_ENV": "production"}],
+				"defaults": {"command": "npm run bench", "env": {"CI": "1"}}
+			}
+		}`))
+		assert.Nil(t, err)
+		assert.Equal(t, len(c.Environments), 8)
+
+		first := c.Environments[0]
+		assert.Equal(t, first.Runtime, "node")
+		assert.Equal(t, first.Version, "8")
+		assert.Equal(t, first.Machine, "local")
+		assert.Equal(t, first.Command, "npm run bench")
+		assert.Equal(t, first.Env, map[string]string{"CI": "1"})
+		assert.Equal(t, c.Environments[1].Env, map[string]string{"CI": "1", "NODE_ENV": "production"})
+		assert.Equal(t, c.Environments[7].Version, "9")
+		assert.Equal(t, c.Environments[7].Machine, "hyper-s4")
+	})
+
+	t.Run("exclude and include", func(t *testing.T) {
+		c, err := ParseConfig([]byte(`{
+			"environments": [{"runtime": "golang", "machine": "local"}],
+			"matrix": {
+				"runtimes": ["ruby"],
+				"versions": ["2.3", "2.4"],
+				"machines": ["local", "hyper-s1"],
+				"defaults": {"command": "ruby bench.rb"},
+				"exclude": [{"version": "2.3", "machine": "hyper-s1"}],
+				"include": [{"runtime": "jruby", "machine": "local"}]
+			}
+		}`))
+		assert.Nil(t, err)
+		assert.Equal(t, len(c.Environments), 5)
+		assert.Equal(t, c.Environments[0].Runtime, "golang")
+		assert.Equal(t, c.Environments[2].Version, "2.4")
+		assert.Equal(t, c.Environments[4].Runtime, "jruby")
+		assert.Equal(t, c.Environments[4].Command, "ruby bench.rb")
+	})
+
+	t.Run("unmatched exclude", func(t *testing.T) {
+		_, err := ParseConfig([]byte(`{
+			"matrix": {
+				"runtimes": ["ruby"],
+				"machines": ["local"],
+				"exclude": [{"machine": "hyper-s1"}]
+			}
+		}`))
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "matrix error: matrix exclude 0 matches no environment")
+	})
+
+	t.Run("points at the matrix entry", func(t *testing.T) {
+		_, err := ParseConfig([]byte(`{
+			"matrix": {
+				"runtimes": ["golang"],
+				"versions": ["1.9"],
+				"machines": ["local", "hyper-s9"]
+			}
+		}`))
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "validation error: environment 1 (matrix runtime=golang version=1.9 machine=hyper-s9): invalid machine size: hyper-s9")
+	})
+
+	t.Run("points at the include entry", func(t *testing.T) {
+		_, err := ParseConfig([]byte(`{
+			"matrix": {
+				"runtimes": ["golang"],
+				"machines": ["local"],
+				"include": [{"machine": "local", "parser": "minitest"}]
+			}
+		}`))
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "validation error: environment 1 (matrix include 0) runtime can't be blank")
+	})
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Head returns the commit checked out on the repository at `dir`
+func Head(dir string) (string, error) {
+	return run(dir, "rev-parse", "HEAD")
+}
+
+// Resolve returns the commit `rev` points to, ie: main, HEAD~2 or a tag
+func Resolve(dir, rev string) (string, error) {
+	return run(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+// Range lists the commits after `from` up to and including `to`, oldest first
+func Range(dir, from, to string) ([]string, error) {
+	out, err := run(dir, "rev-list", "--reverse", "--ancestry-path", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// Subject returns the first line of the commit message
+func Subject(dir, rev string) (string, error) {
+	return run(dir, "log", "-1", "--format=%s", rev)
+}
+
+// AddWorktree checks out `rev` on a new temporary worktree of the repository
+// at `dir`, returning its path. Worktrees must be removed with RemoveWorktree.
+func AddWorktree(dir, rev string) (string, error) {
+
+	path, err := ioutil.TempDir("", "ben-rev-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed creating worktree dir")
+	}
+
+	if _, err := run(dir, "worktree", "add", "--detach", path, rev); err != nil {
+		os.RemoveAll(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// RemoveWorktree removes a worktree created by AddWorktree
+func RemoveWorktree(dir, path string) error {
+	if _, err := run(dir, "worktree", "remove", "--force", path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// runs a git command on `dir` returning its trimmed stdout
+func run(dir string, args ...string) (string, error) {
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", errors.Errorf("git %s failed: %s", args[0], msg)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// creates a repository with a single commit
+func testRepo(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "ben-git")
+	assert.Nil(t, err)
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=ben", "-c", "user.email=ben@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+	} {
+		_, err := run(dir, args...)
+		assert.Nil(t, err)
+	}
+	return dir
+}
+
+func TestGit_Head(t *testing.T) {
+
+	t.Run("repository", func(t *testing.T) {
+		dir := testRepo(t)
+		defer os.RemoveAll(dir)
+
+		head, err := Head(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, len(head), 40)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "ben-git")
+		defer os.RemoveAll(dir)
+
+		_, err := Head(dir)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGit_Resolve(t *testing.T) {
+	dir := testRepo(t)
+	defer os.RemoveAll(dir)
+
+	head, _ := Head(dir)
+
+	commit, err := Resolve(dir, "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, commit, head)
+
+	_, err = Resolve(dir, "missing")
+	assert.NotNil(t, err)
+}
+
+func TestGit_Worktree(t *testing.T) {
+	dir := testRepo(t)
+	defer os.RemoveAll(dir)
+
+	// second commit adding a file
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "bench.txt"), []byte("v2"), 0644))
+	for _, args := range [][]string{
+		{"add", "bench.txt"},
+		{"-c", "user.name=ben", "-c", "user.email=ben@example.com", "commit", "-q", "-m", "second"},
+	} {
+		_, err := run(dir, args...)
+		assert.Nil(t, err)
+	}
+
+	t.Run("checks out the revision", func(t *testing.T) {
+		path, err := AddWorktree(dir, "HEAD~1")
+		assert.Nil(t, err)
+
+		_, err = os.Stat(filepath.Join(path, "bench.txt"))
+		assert.True(t, os.IsNotExist(err))
+
+		assert.Nil(t, RemoveWorktree(dir, path))
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		_, err := AddWorktree(dir, "missing")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGit_Range(t *testing.T) {
+	dir := testRepo(t)
+	defer os.RemoveAll(dir)
+
+	first, _ := Head(dir)
+	for _, msg := range []string{"second", "third"} {
+		_, err := run(dir, "-c", "user.name=ben", "-c", "user.email=ben@example.com", "commit", "-q", "--allow-empty", "-m", msg)
+		assert.Nil(t, err)
+	}
+
+	commits, err := Range(dir, first, "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, len(commits), 2)
+
+	subject, err := Subject(dir, commits[0])
+	assert.Nil(t, err)
+	assert.Equal(t, subject, "second")
+
+	commits, err = Range(dir, "HEAD", "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, len(commits), 0)
+}
@@ -0,0 +1,116 @@
+package history
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drish/ben/reporter"
+	"github.com/drish/ben/utils"
+	"github.com/pkg/errors"
+)
+
+// DefaultDir is where runs are stored, relative to the project root
+const DefaultDir = ".ben/history"
+
+// Entry is a single run stored on the history
+type Entry struct {
+	ID         string          `json:"id"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Commit     string          `json:"commit"`     // project commit, blank outside git repositories
+	ConfigHash string          `json:"configHash"` // hash of the ben.json the run used
+	Report     reporter.Report `json:"report"`
+}
+
+// ErrNoHistory is returned when opening a missing history read only
+var ErrNoHistory = errors.New("no history")
+
+// Store is an append only, one json file per run, history of benchmark runs
+type Store struct {
+	Dir string
+}
+
+// Open creates the store directory if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed creating history dir")
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// OpenReadOnly opens an existing store without creating its directory
+func OpenReadOnly(dir string) (*Store, error) {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return nil, ErrNoHistory
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening history dir")
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("history dir %s is not a directory", dir)
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// Append stores a new run, the entry id and timestamp are set if blank
+func (s *Store) Append(e *Entry) error {
+
+	if e.ID == "" {
+		e.ID = strings.ToLower(utils.RandString(8))
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed encoding history entry")
+	}
+
+	// file names sort in chronological order
+	name := e.Timestamp.UTC().Format("20060102T150405Z") + "-" + e.ID + ".json"
+
+	// write and rename so readers never see partial entries
+	tmp := filepath.Join(s.Dir, name+".tmp")
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return errors.Wrap(err, "failed writing history entry")
+	}
+	if err := os.Rename(tmp, filepath.Join(s.Dir, name)); err != nil {
+		return errors.Wrap(err, "failed writing history entry")
+	}
+	return nil
+}
+
+// Entries returns every stored run, oldest first
+func (s *Store) Entries() ([]Entry, error) {
+
+	files, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing history")
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading history entry")
+		}
+
+		var e Entry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, errors.Wrapf(err, "invalid history entry %s", filepath.Base(f))
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drish/ben/reporter"
+	"github.com/drish/ben/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func report(mean float64) reporter.Report {
+	return reporter.NewReport([]reporter.ReportData{
+		{
+			Image:   "golang:1.9",
+			Machine: "local",
+			Summaries: []reporter.Summary{
+				{Benchmark: "BenchmarkFib10", Unit: "ns/op", Summary: stats.Summarize([]float64{mean})},
+				{Benchmark: "BenchmarkFib10", Unit: "B/op", Summary: stats.Summarize([]float64{0})},
+			},
+		},
+	}, 0)
+}
+
+func TestStore(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ben-history")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = OpenReadOnly(filepath.Join(dir, ".ben", "history"))
+	assert.Equal(t, err, ErrNoHistory)
+	_, err = os.Stat(filepath.Join(dir, ".ben"))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	s, err := Open(filepath.Join(dir, ".ben", "history"))
+	assert.Nil(t, err)
+
+	_, err = OpenReadOnly(s.Dir)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	assert.Nil(t, s.Append(&Entry{Timestamp: now, Commit: "bbbbbbbbbb", Report: report(398)}))
+	assert.Nil(t, s.Append(&Entry{Timestamp: now.Add(-time.Hour), Commit: "aaaaaaaaaa", Report: report(413)}))
+
+	e := &Entry{Report: report(400)}
+	assert.Nil(t, s.Append(e))
+	assert.NotEqual(t, e.ID, "")
+	assert.Equal(t, e.Timestamp.IsZero(), false)
+
+	entries, err := s.Entries()
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].Commit, "aaaaaaaaaa")
+	assert.Equal(t, entries[1].Commit, "bbbbbbbbbb")
+	assert.Equal(t, entries[0].Report.Environments[0].Summaries[0].Mean, 413.0)
+
+	t.Run("trend", func(t *testing.T) {
+		series := Trend(entries, "BenchmarkFib10", "")
+		assert.Equal(t, len(series), 1)
+		assert.Equal(t, series[0].Environment, "golang:1.9 (local)")
+		assert.Equal(t, series[0].Unit, "ns/op")
+		assert.Equal(t, len(series[0].Points), 3)
+		assert.Equal(t, series[0].Points[1].Mean, 398.0)
+
+		assert.Equal(t, len(Trend(entries, "BenchmarkFib10", "B/op")), 1)
+		assert.Equal(t, len(Trend(entries, "BenchmarkFib20", "")), 0)
+	})
+
+	t.Run("benchmarks", func(t *testing.T) {
+		assert.Equal(t, Benchmarks(entries), []string{"BenchmarkFib10 (ns/op)", "BenchmarkFib10 (B/op)"})
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, WriteTable(&buf, Trend(entries, "BenchmarkFib10", "")))
+		assert.Equal(t, strings.Contains(buf.String(), "aaaaaaaa"), true)
+		assert.Equal(t, strings.Contains(buf.String(), "-3.63%"), true)
+	})
+
+	t.Run("chart", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, WriteChart(&buf, Trend(entries, "BenchmarkFib10", "")))
+		assert.Equal(t, strings.Contains(buf.String(), strings.Repeat("█", chartWidth)), true)
+	})
+
+	t.Run("invalid entry", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(s.Dir, "broken.json"), []byte("{"), 0644)
+		_, err := s.Entries()
+		assert.NotNil(t, err)
+	})
+}
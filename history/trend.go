@@ -0,0 +1,164 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/drish/ben/reporter"
+)
+
+// width of the longest bar on trend charts
+const chartWidth = 40
+
+// Point is the value of a benchmark metric on a single run
+type Point struct {
+	Timestamp time.Time
+	Commit    string
+	N         int
+	Mean      float64
+	CILow     float64
+	CIHigh    float64
+}
+
+// Series is a benchmark metric over time on a single environment
+type Series struct {
+	Environment string
+	Benchmark   string
+	Unit        string
+	Points      []Point
+}
+
+// Trend returns one series per environment for the benchmark metric,
+// if `unit` is blank the first unit found for the benchmark is used
+func Trend(entries []Entry, benchmark, unit string) []Series {
+
+	var series []Series
+	index := map[string]int{}
+
+	for _, e := range entries {
+		for _, env := range e.Report.Environments {
+			for _, s := range env.Summaries {
+				if s.Benchmark != benchmark {
+					continue
+				}
+				if unit == "" {
+					unit = s.Unit
+				}
+				if s.Unit != unit {
+					continue
+				}
+
+				label := env.Label()
+				i, ok := index[label]
+				if !ok {
+					i = len(series)
+					index[label] = i
+					series = append(series, Series{
+						Environment: label,
+						Benchmark:   benchmark,
+						Unit:        unit,
+					})
+				}
+
+				series[i].Points = append(series[i].Points, Point{
+					Timestamp: e.Timestamp,
+					Commit:    e.Commit,
+					N:         s.N,
+					Mean:      s.Mean,
+					CILow:     s.CILow,
+					CIHigh:    s.CIHigh,
+				})
+			}
+		}
+	}
+
+	return series
+}
+
+// Benchmarks lists every benchmark and unit found on the history
+func Benchmarks(entries []Entry) []string {
+
+	var names []string
+	seen := map[string]bool{}
+
+	for _, e := range entries {
+		for _, env := range e.Report.Environments {
+			for _, s := range env.Summaries {
+				name := s.Benchmark + " (" + s.Unit + ")"
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// WriteTable writes the series as a table, with the change against the previous run
+func WriteTable(w io.Writer, series []Series) error {
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, s := range series {
+		fmt.Fprintf(tw, "%s\t%s (%s)\n", s.Environment, s.Benchmark, s.Unit)
+		fmt.Fprintln(tw, "date\tcommit\truns\tmean\t95% CI\tchange")
+
+		for i, p := range s.Points {
+			change := "-"
+			if i > 0 && s.Points[i-1].Mean != 0 {
+				prev := s.Points[i-1].Mean
+				change = fmt.Sprintf("%+.2f%%", (p.Mean-prev)/prev*100)
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s - %s\t%s\n",
+				p.Timestamp.Local().Format("2006-01-02 15:04"), shortCommit(p.Commit), p.N,
+				reporter.FormatValue(p.Mean), reporter.FormatValue(p.CILow), reporter.FormatValue(p.CIHigh), change)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	return tw.Flush()
+}
+
+// WriteChart writes the series as horizontal bar charts, one bar per run
+func WriteChart(w io.Writer, series []Series) error {
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, s := range series {
+		max := 0.0
+		for _, p := range s.Points {
+			if p.Mean > max {
+				max = p.Mean
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s (%s)\n", s.Environment, s.Benchmark, s.Unit)
+		for _, p := range s.Points {
+			n := 0
+			if max > 0 {
+				n = int(p.Mean / max * chartWidth)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s %s\n",
+				p.Timestamp.Local().Format("2006-01-02 15:04"), shortCommit(p.Commit),
+				strings.Repeat("█", n), reporter.FormatValue(p.Mean))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	return tw.Flush()
+}
+
+func shortCommit(commit string) string {
+	if commit == "" {
+		return "-"
+	}
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
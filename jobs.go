@@ -0,0 +1,138 @@
+package ben
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/drish/ben/builders"
+	"github.com/drish/ben/config"
+	"github.com/drish/ben/reporter"
+	"github.com/drish/ben/utils"
+	"github.com/fatih/color"
+)
+
+// job is an environment benchmarked on a source
+type job struct {
+	env    config.Environment
+	src    source
+	labels map[string]string // set on the resources created by the job
+}
+
+// label identifies the job on the terminal output, ie: golang:1.9 (hyper-s1) @ main
+func (j job) label() string {
+	d := reporter.ReportData{
+		Image:    utils.PrepareImage(j.env.Runtime, j.env.Version),
+		Machine:  j.env.Machine,
+		Env:      utils.ReportEnv(j.env.Env, j.env.PassEnv),
+		Revision: j.src.Revision,
+	}
+	return d.Label()
+}
+
+// runs up to `opts.Parallel` jobs at once, local jobs run one at a time
+// unless `opts.ParallelLocal` is set. Reports are returned in jobs order,
+// after the first failure no further jobs are started, unless `opts.KeepGoing`
+// is set, in which case failed jobs are reported with their failure.
+func (r *Runner) runJobs(ctx context.Context, jobs []job, opts Options) ([]reporter.ReportData, error) {
+
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	reports := make([]reporter.ReportData, len(jobs))
+	errs := make([]error, len(jobs))
+
+	var (
+		wg     sync.WaitGroup
+		local  sync.Mutex // held by running local jobs
+		outMu  sync.Mutex // shared by prefixed outputs
+		mu     sync.Mutex // guards `failed`
+		failed bool
+	)
+	slots := make(chan struct{}, parallel)
+
+	for i, j := range jobs {
+		wg.Add(1)
+		go func(i int, j job) {
+			defer wg.Done()
+
+			// local jobs wait for each other before taking a slot,
+			// so they don't hold slots other machines could use
+			if b, _, _ := builders.Lookup(j.env.Machine); b.Local && !opts.ParallelLocal {
+				local.Lock()
+				defer local.Unlock()
+			}
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// jobs waiting on a slot don't start after a failure or an interrupt
+			mu.Lock()
+			skip := failed
+			mu.Unlock()
+			if skip || ctx.Err() != nil {
+				return
+			}
+
+			// concurrent output is written line by line, labelled by job
+			var out io.Writer = os.Stdout
+			if parallel > 1 {
+				out = utils.NewPrefixWriter(os.Stdout, &outMu, "  ["+j.label()+"] ")
+			}
+
+			// output is captured to be reported on failures
+			var logs bytes.Buffer
+			out = io.MultiWriter(out, utils.NewPrefixWriter(&logs, &sync.Mutex{}, ""))
+
+			// machines are validated with the config, so this only fails on unregistered backends
+			b, err := newBuilder(j.env, j.src.Dir, j.labels, out)
+			if err != nil {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+				errs[i] = err
+				return
+			}
+
+			rp, err := r.BuildRuntime(ctx, b, j.env, opts.Display, out)
+			rp.Revision = j.src.Revision
+			rp.Commit = j.src.Commit
+
+			if err != nil {
+				// interrupted jobs are reported as failures on the partial report
+				if opts.KeepGoing || ctx.Err() != nil {
+					rp.Failure.Logs = logs.String() + rp.Failure.Logs
+					fmt.Fprintf(out, "\n  \033[36m%s failed on %s \033[m %s\n\n", j.label(), rp.Failure.Phase, color.RedString(err.Error()))
+					reports[i] = rp
+					return
+				}
+
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+				errs[i] = err
+				return
+			}
+
+			reports[i] = rp
+		}(i, j)
+
+		// serial runs start jobs in order
+		if parallel == 1 {
+			wg.Wait()
+		}
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,55 @@
+package parsers
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// matches benchmark.js cycle lines, ie:
+//
+// sort-array x 18,058 ops/sec ±2.08% (74 runs sampled)
+var benchmarkJSLine = regexp.MustCompile(`^\s*(.+?) x ([\d,.]+) ops/sec ±([\d.]+)% \((\d+) runs? sampled\)`)
+
+// BenchmarkJSParser parses Node's benchmark.js output
+type BenchmarkJSParser struct{}
+
+// Parse returns one benchmark for every cycle line found on the output
+func (p *BenchmarkJSParser) Parse(output string) ([]Benchmark, error) {
+
+	var benchmarks []Benchmark
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		m := benchmarkJSLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		ops, err := strconv.ParseFloat(strings.Replace(m[2], ",", "", -1), 64)
+		if err != nil {
+			continue
+		}
+		deviation, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			continue
+		}
+
+		benchmarks = append(benchmarks, Benchmark{
+			Name: m[1],
+			Metrics: []Metric{
+				{Value: ops, Unit: "ops/sec", Deviation: deviation, HigherIsBetter: true},
+			},
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(benchmarks) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+	return benchmarks, nil
+}
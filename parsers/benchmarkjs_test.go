@@ -0,0 +1,35 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBenchmarkJSParser_Parse(t *testing.T) {
+
+	t.Run("cycle lines", func(t *testing.T) {
+		output := `
+> @ bench /tmp
+> node index.js
+
+sort-array x 18,058 ops/sec ±2.08% (74 runs sampled)
+array-sort x 101,135 ops/sec ±1.87% (77 runs sampled)
+Fastest is array-sort
+`
+		p := &BenchmarkJSParser{}
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 2)
+		assert.Equal(t, b[0].Name, "sort-array")
+		assert.Equal(t, b[0].Metrics, []Metric{{Value: 18058, Unit: "ops/sec", Deviation: 2.08, HigherIsBetter: true}})
+		assert.Equal(t, b[1].Name, "array-sort")
+		assert.Equal(t, b[1].Metrics[0].String(), "101135 ops/sec ±1.87%")
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		p := &BenchmarkJSParser{}
+		_, err := p.Parse("npm ERR! missing script: bench")
+		assert.Equal(t, err, ErrNoBenchmarks)
+	})
+}
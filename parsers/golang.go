@@ -0,0 +1,75 @@
+package parsers
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// GoParser parses `go test -bench` output, ie:
+//
+// BenchmarkFib10-4   	 3000000	       413 ns/op	       0 B/op	       0 allocs/op
+type GoParser struct{}
+
+// Parse returns one benchmark for every result line found on the output
+func (p *GoParser) Parse(output string) ([]Benchmark, error) {
+
+	var benchmarks []Benchmark
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if b, ok := parseGoLine(scanner.Text()); ok {
+			benchmarks = append(benchmarks, b)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(benchmarks) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+	return benchmarks, nil
+}
+
+// parses a single result line, lines that are not results are skipped
+func parseGoLine(line string) (Benchmark, bool) {
+
+	fields := strings.Fields(line)
+
+	// name, iterations and at least one value/unit pair
+	if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+		return Benchmark{}, false
+	}
+
+	iterations, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return Benchmark{}, false
+	}
+
+	b := Benchmark{
+		Name:       fields[0],
+		Iterations: iterations,
+	}
+
+	// strip the GOMAXPROCS suffix so results from
+	// machines with different cpu counts line up, ie: BenchmarkFib10-4
+	if i := strings.LastIndex(b.Name, "-"); i > 0 {
+		if procs, err := strconv.Atoi(b.Name[i+1:]); err == nil {
+			b.Name = b.Name[:i]
+			b.Procs = procs
+		}
+	}
+
+	// value/unit pairs, including custom b.ReportMetric units
+	for i := 2; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Benchmark{}, false
+		}
+		b.Metrics = append(b.Metrics, Metric{Value: value, Unit: fields[i+1]})
+	}
+
+	return b, true
+}
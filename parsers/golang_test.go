@@ -0,0 +1,64 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoParser_Parse(t *testing.T) {
+
+	t.Run("default output", func(t *testing.T) {
+		output := `goos: linux
+goarch: amd64
+BenchmarkFib10-4   	 3000000	       413 ns/op
+BenchmarkFib20-4   	   30000	     51959 ns/op
+PASS
+ok  	_/tmp	3.725s
+`
+		p := &GoParser{}
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 2)
+		assert.Equal(t, b[0].Name, "BenchmarkFib10")
+		assert.Equal(t, b[0].Procs, 4)
+		assert.Equal(t, b[0].Iterations, int64(3000000))
+		assert.Equal(t, b[0].Metrics, []Metric{{Value: 413, Unit: "ns/op"}})
+		assert.Equal(t, b[1].Name, "BenchmarkFib20")
+	})
+
+	t.Run("benchmem and custom metrics", func(t *testing.T) {
+		output := "BenchmarkSort/small-8 \t 1000000\t 1052 ns/op\t 0.50 hits/op\t 64 B/op\t 2 allocs/op\n"
+
+		p := &GoParser{}
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 1)
+		assert.Equal(t, b[0].Name, "BenchmarkSort/small")
+		assert.Equal(t, b[0].Metrics, []Metric{
+			{Value: 1052, Unit: "ns/op"},
+			{Value: 0.5, Unit: "hits/op"},
+			{Value: 64, Unit: "B/op"},
+			{Value: 2, Unit: "allocs/op"},
+		})
+
+		m, ok := b[0].Metric("allocs/op")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, m.String(), "2 allocs/op")
+	})
+
+	t.Run("no procs suffix", func(t *testing.T) {
+		p := &GoParser{}
+		b, err := p.Parse("BenchmarkFib10 \t 3000000 \t 413 ns/op\n")
+		assert.Nil(t, err)
+		assert.Equal(t, b[0].Name, "BenchmarkFib10")
+		assert.Equal(t, b[0].Procs, 0)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		p := &GoParser{}
+		b, err := p.Parse("BenchmarkFib10-4 --- FAIL: BenchmarkFib10\nFAIL\n")
+		assert.Nil(t, b)
+		assert.Equal(t, err, ErrNoBenchmarks)
+	})
+}
@@ -0,0 +1,102 @@
+package parsers
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// matches benchmark-ips report lines, ie:
+//
+//	sort     49.292k (± 5.6%) i/s -    985.266k in  20.056790s
+var ipsLine = regexp.MustCompile(`^\s*(?:(.*\S)\s+)?([\d.]+[kMBTQ]?)\s+\(±\s*([\d.]+)%\)\s+i/s(?:\s+\([^)]*\))?\s+-\s+([\d.]+[kMBTQ]?)\s+in\s`)
+
+// benchmark-ips humanized number suffixes
+var ipsScale = map[string]float64{
+	"k": 1e3,
+	"M": 1e6,
+	"B": 1e9,
+	"T": 1e12,
+	"Q": 1e15,
+}
+
+// IPSParser parses Ruby's benchmark-ips output
+type IPSParser struct{}
+
+// Parse returns one benchmark for every report found on the output
+func (p *IPSParser) Parse(output string) ([]Benchmark, error) {
+
+	var benchmarks []Benchmark
+
+	// labels longer than 20 chars are printed on their own line
+	pending := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		m := ipsLine.FindStringSubmatch(line)
+		if m == nil {
+			pending = strings.TrimSpace(line)
+			if strings.HasPrefix(pending, "Warming up") || strings.HasPrefix(pending, "Calculating") {
+				pending = ""
+			}
+			continue
+		}
+
+		name := m[1]
+		if name == "" {
+			name = pending
+		}
+		if name == "" {
+			name = "benchmark"
+		}
+		pending = ""
+
+		ips, err := parseIPSNumber(m[2])
+		if err != nil {
+			continue
+		}
+		deviation, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			continue
+		}
+		iterations, err := parseIPSNumber(m[4])
+		if err != nil {
+			continue
+		}
+
+		benchmarks = append(benchmarks, Benchmark{
+			Name:       name,
+			Iterations: int64(iterations),
+			Metrics: []Metric{
+				{Value: ips, Unit: "i/s", Deviation: deviation, HigherIsBetter: true},
+			},
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(benchmarks) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+	return benchmarks, nil
+}
+
+// parses humanized numbers, ie: 49.292k
+func parseIPSNumber(s string) (float64, error) {
+	scale := 1.0
+	if f, ok := ipsScale[s[len(s)-1:]]; ok {
+		scale = f
+		s = s[:len(s)-1]
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return v * scale, nil
+}
@@ -0,0 +1,48 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPSParser_Parse(t *testing.T) {
+
+	t.Run("unlabeled report", func(t *testing.T) {
+		output := `Warming up --------------------------------------
+                         3.879k i/100ms
+Calculating -------------------------------------
+                         49.292k (± 5.6%) i/s -    985.266k in  20.056790s
+`
+		p := &IPSParser{}
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 1)
+		assert.Equal(t, b[0].Name, "benchmark")
+		assert.Equal(t, b[0].Iterations, int64(985266))
+		assert.Equal(t, b[0].Metrics, []Metric{{Value: 49292, Unit: "i/s", Deviation: 5.6, HigherIsBetter: true}})
+	})
+
+	t.Run("labeled reports", func(t *testing.T) {
+		output := `Calculating -------------------------------------
+               inject     52.179k (± 3.0%) i/s -      1.046M in  20.071550s
+a very long benchmark label
+                          1.2k (±10.1%) i/s   (833.33 μs/i) -     24.000k in  20.000000s
+`
+		p := &IPSParser{}
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 2)
+		assert.Equal(t, b[0].Name, "inject")
+		assert.Equal(t, b[0].Iterations, int64(1046000))
+		assert.Equal(t, b[1].Name, "a very long benchmark label")
+		assert.Equal(t, b[1].Metrics[0].Value, 1200.0)
+		assert.Equal(t, b[1].Metrics[0].Deviation, 10.1)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		p := &IPSParser{}
+		_, err := p.Parse("ruby: No such file or directory -- bench.rb (LoadError)")
+		assert.Equal(t, err, ErrNoBenchmarks)
+	})
+}
@@ -0,0 +1,50 @@
+package parsers
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoBenchmarks is returned when no benchmark results could be found on the output
+var ErrNoBenchmarks = errors.New("no benchmark results found")
+
+// Parser is the interface that defines how to turn raw benchmark output into benchmarks
+type Parser interface {
+	Parse(output string) ([]Benchmark, error)
+}
+
+// Metric is a single measurement of a benchmark, ie: 413 ns/op
+type Metric struct {
+	Value          float64 `json:"value"`
+	Unit           string  `json:"unit"`
+	Deviation      float64 `json:"deviation,omitempty"` // relative deviation in percent, when reported by the tool
+	HigherIsBetter bool    `json:"higherIsBetter"`      // true for throughput units, ie: ops/sec
+}
+
+// Benchmark is a single benchmark result parsed from the benchmark output
+type Benchmark struct {
+	Name       string   `json:"name"`                 // benchmark name, ie: BenchmarkFib10
+	Procs      int      `json:"procs,omitempty"`      // GOMAXPROCS the benchmark ran with, when reported
+	Iterations int64    `json:"iterations,omitempty"` // number of iterations, when reported
+	Metrics    []Metric `json:"metrics"`              // measurements, ie: ns/op, B/op, allocs/op
+}
+
+// String formats the metric the same way it is printed by benchmark tools
+func (m Metric) String() string {
+	s := strconv.FormatFloat(m.Value, 'f', -1, 64) + " " + m.Unit
+	if m.Deviation > 0 {
+		s += " ±" + strconv.FormatFloat(m.Deviation, 'f', 2, 64) + "%"
+	}
+	return s
+}
+
+// Metric returns the benchmark metric with the given unit
+func (b Benchmark) Metric(unit string) (Metric, bool) {
+	for _, m := range b.Metrics {
+		if m.Unit == unit {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
@@ -0,0 +1,62 @@
+package parsers
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// subset of the pytest-benchmark json report
+type pytestReport struct {
+	Benchmarks []struct {
+		Name  string `json:"name"`
+		Stats struct {
+			Mean   float64 `json:"mean"`
+			StdDev float64 `json:"stddev"`
+			Ops    float64 `json:"ops"`
+			Rounds int64   `json:"rounds"`
+		} `json:"stats"`
+	} `json:"benchmarks"`
+}
+
+// PytestParser parses pytest-benchmark json reports,
+// ie: the output of `pytest --benchmark-json=/dev/stdout`
+type PytestParser struct{}
+
+// Parse returns one benchmark for every entry of the json report
+func (p *PytestParser) Parse(output string) ([]Benchmark, error) {
+
+	// the json report may be surrounded by regular pytest output
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start == -1 || end < start {
+		return nil, ErrNoBenchmarks
+	}
+
+	var report pytestReport
+	if err := json.Unmarshal([]byte(output[start:end+1]), &report); err != nil {
+		return nil, err
+	}
+
+	var benchmarks []Benchmark
+	for _, b := range report.Benchmarks {
+
+		deviation := 0.0
+		if b.Stats.Mean > 0 {
+			deviation = b.Stats.StdDev / b.Stats.Mean * 100
+		}
+
+		benchmarks = append(benchmarks, Benchmark{
+			Name:       b.Name,
+			Iterations: b.Stats.Rounds,
+			Metrics: []Metric{
+				{Value: b.Stats.Mean, Unit: "s/op", Deviation: deviation},
+				{Value: b.Stats.Ops, Unit: "ops/sec", HigherIsBetter: true},
+			},
+		})
+	}
+
+	if len(benchmarks) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+	return benchmarks, nil
+}
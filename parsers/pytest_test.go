@@ -0,0 +1,51 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPytestParser_Parse(t *testing.T) {
+
+	t.Run("json report", func(t *testing.T) {
+		output := `============ test session starts ============
+{
+  "benchmarks": [
+    {
+      "name": "test_fib",
+      "stats": {"min": 0.0009, "max": 0.0012, "mean": 0.001, "stddev": 0.0001, "rounds": 500, "ops": 1000.0}
+    }
+  ]
+}
+============ 1 passed in 1.52s ============
+`
+		p := &PytestParser{}
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 1)
+		assert.Equal(t, b[0].Name, "test_fib")
+		assert.Equal(t, b[0].Iterations, int64(500))
+
+		m, ok := b[0].Metric("s/op")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, m.Value, 0.001)
+		assert.InDelta(t, m.Deviation, 10, 0.0001)
+
+		m, ok = b[0].Metric("ops/sec")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, m.HigherIsBetter, true)
+	})
+
+	t.Run("no json", func(t *testing.T) {
+		p := &PytestParser{}
+		_, err := p.Parse("ERROR: file not found: bench_test.py")
+		assert.Equal(t, err, ErrNoBenchmarks)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		p := &PytestParser{}
+		_, err := p.Parse("{ not json }")
+		assert.NotNil(t, err)
+	})
+}
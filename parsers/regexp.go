@@ -0,0 +1,79 @@
+package parsers
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// RegexpRule extracts results from the output with a regular expression.
+// The expression must have a `value` group and may have `name` and `unit` groups.
+type RegexpRule struct {
+	Name           string         // benchmark name, used when there's no `name` group
+	Regexp         *regexp.Regexp // compiled expression
+	Unit           string         // unit, used when there's no `unit` group
+	HigherIsBetter bool
+}
+
+// RegexpParser parses arbitrary output using user defined rules
+type RegexpParser struct {
+	Rules []RegexpRule
+}
+
+// NewRegexpParser creates a new regexp parser
+func NewRegexpParser(rules []RegexpRule) *RegexpParser {
+	return &RegexpParser{
+		Rules: rules,
+	}
+}
+
+// Parse applies every rule to the output, metrics with the same name are grouped together
+func (p *RegexpParser) Parse(output string) ([]Benchmark, error) {
+
+	var benchmarks []Benchmark
+	index := map[string]int{}
+
+	for _, rule := range p.Rules {
+		names := rule.Regexp.SubexpNames()
+
+		for _, match := range rule.Regexp.FindAllStringSubmatch(output, -1) {
+			name, unit, value := rule.Name, rule.Unit, ""
+			for i, group := range names {
+				switch group {
+				case "name":
+					if match[i] != "" {
+						name = match[i]
+					}
+				case "unit":
+					if match[i] != "" {
+						unit = match[i]
+					}
+				case "value":
+					value = match[i]
+				}
+			}
+
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			i, ok := index[name]
+			if !ok {
+				i = len(benchmarks)
+				index[name] = i
+				benchmarks = append(benchmarks, Benchmark{Name: name})
+			}
+
+			benchmarks[i].Metrics = append(benchmarks[i].Metrics, Metric{
+				Value:          v,
+				Unit:           unit,
+				HigherIsBetter: rule.HigherIsBetter,
+			})
+		}
+	}
+
+	if len(benchmarks) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+	return benchmarks, nil
+}
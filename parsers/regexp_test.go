@@ -0,0 +1,52 @@
+package parsers
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexpParser_Parse(t *testing.T) {
+
+	output := `run 1
+GET /users latency: 12.5 ms
+GET /posts latency: 30 ms
+throughput: 1520 req/s
+`
+
+	t.Run("name, value and unit groups", func(t *testing.T) {
+		p := NewRegexpParser([]RegexpRule{
+			{Regexp: regexp.MustCompile(`GET (?P<name>\S+) latency: (?P<value>[\d.]+) (?P<unit>\w+)`)},
+		})
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 2)
+		assert.Equal(t, b[0].Name, "/users")
+		assert.Equal(t, b[0].Metrics, []Metric{{Value: 12.5, Unit: "ms"}})
+		assert.Equal(t, b[1].Name, "/posts")
+	})
+
+	t.Run("rule name and unit", func(t *testing.T) {
+		p := NewRegexpParser([]RegexpRule{
+			{Name: "server", Regexp: regexp.MustCompile(`throughput: (?P<value>\d+)`), Unit: "req/s", HigherIsBetter: true},
+			{Name: "server", Regexp: regexp.MustCompile(`/users latency: (?P<value>[\d.]+)`), Unit: "ms"},
+		})
+		b, err := p.Parse(output)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), 1)
+		assert.Equal(t, b[0].Name, "server")
+		assert.Equal(t, b[0].Metrics, []Metric{
+			{Value: 1520, Unit: "req/s", HigherIsBetter: true},
+			{Value: 12.5, Unit: "ms"},
+		})
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		p := NewRegexpParser([]RegexpRule{
+			{Name: "x", Regexp: regexp.MustCompile(`p99: (?P<value>\d+)`)},
+		})
+		_, err := p.Parse(output)
+		assert.Equal(t, err, ErrNoBenchmarks)
+	})
+}
@@ -0,0 +1,52 @@
+package parsers
+
+import "sync"
+
+var (
+	mu sync.RWMutex
+
+	// parsers by name
+	registry = map[string]Parser{}
+
+	// parser names by runtime
+	runtimes = map[string]string{}
+)
+
+func init() {
+	Register("go", &GoParser{}, "golang")
+	Register("benchmark-ips", &IPSParser{}, "ruby", "jruby")
+	Register("benchmark.js", &BenchmarkJSParser{}, "node")
+	Register("pytest-benchmark", &PytestParser{}, "python", "pypy")
+}
+
+// Register adds a parser under `name`, making it the default for the given runtimes
+func Register(name string, p Parser, runtime ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registry[name] = p
+	for _, r := range runtime {
+		runtimes[r] = name
+	}
+}
+
+// Lookup returns the parser registered under `name`
+func Lookup(name string) (Parser, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, ok := registry[name]
+	return p, ok
+}
+
+// ForRuntime returns the default parser for the specified runtime
+func ForRuntime(runtime string) (Parser, bool) {
+	mu.RLock()
+	name, ok := runtimes[runtime]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return Lookup(name)
+}
@@ -0,0 +1,34 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_ForRuntime(t *testing.T) {
+
+	t.Run("known runtime", func(t *testing.T) {
+		p, ok := ForRuntime("jruby")
+		assert.Equal(t, ok, true)
+		assert.IsType(t, &IPSParser{}, p)
+	})
+
+	t.Run("unknown runtime", func(t *testing.T) {
+		p, ok := ForRuntime("elixir")
+		assert.Equal(t, ok, false)
+		assert.Nil(t, p)
+	})
+}
+
+func TestRegistry_Register(t *testing.T) {
+	Register("custom", &GoParser{}, "custom-runtime")
+
+	p, ok := Lookup("custom")
+	assert.Equal(t, ok, true)
+	assert.IsType(t, &GoParser{}, p)
+
+	p, ok = ForRuntime("custom-runtime")
+	assert.Equal(t, ok, true)
+	assert.IsType(t, &GoParser{}, p)
+}
@@ -0,0 +1,248 @@
+package regression
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drish/ben/reporter"
+	"github.com/pkg/errors"
+)
+
+// DefaultThreshold is the allowed slowdown when none is configured
+const DefaultThreshold = 0.05
+
+// ErrRegression is returned when any metric regressed beyond its threshold
+var ErrRegression = errors.New("performance regression detected")
+
+// ErrMissing is returned when baseline benchmarks are missing from the run
+var ErrMissing = errors.New("baseline benchmarks missing from the run")
+
+// ParseThreshold parses a percentage, ie: "5%" or "2.5", into a fraction
+func ParseThreshold(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid threshold: %s", s)
+	}
+	if v < 0 {
+		return 0, errors.Errorf("threshold can't be negative: %s", s)
+	}
+	return v / 100, nil
+}
+
+// Thresholds holds the allowed slowdown, as a fraction, of every benchmark
+type Thresholds struct {
+	Default    float64
+	Benchmarks map[string]float64
+}
+
+// For returns the threshold of the benchmark
+func (t Thresholds) For(benchmark string) float64 {
+	if v, ok := t.Benchmarks[benchmark]; ok {
+		return v
+	}
+	return t.Default
+}
+
+// Result is a benchmark metric compared against the baseline
+type Result struct {
+	Environment    string
+	Benchmark      string
+	Unit           string
+	HigherIsBetter bool
+	Baseline       float64
+	Current        float64
+	Delta          float64 // relative change against the baseline
+	Threshold      float64
+	Regressed      bool
+}
+
+// Unmatched is an environment, or one of its benchmarks, found on
+// only one of the reports, the benchmark is empty for whole environments
+type Unmatched struct {
+	Environment string
+	Benchmark   string
+	Unit        string
+}
+
+func (u Unmatched) String() string {
+	if u.Benchmark == "" {
+		return u.Environment
+	}
+	return fmt.Sprintf("%s %s (%s)", u.Environment, u.Benchmark, u.Unit)
+}
+
+// Comparison is the outcome of checking a run against its baseline
+type Comparison struct {
+	Results []Result
+	Missing []Unmatched // on the baseline but not on the run
+	New     []Unmatched // on the run but not on the baseline
+}
+
+// Check compares the mean of every metric on `current` against the same
+// environment and benchmark on `baseline`, environments are matched by label
+func Check(baseline, current reporter.Report, t Thresholds) Comparison {
+
+	var c Comparison
+
+	for _, env := range current.Environments {
+		base, ok := findEnvironment(baseline, env.Label())
+		if !ok {
+			c.New = append(c.New, Unmatched{Environment: env.Label()})
+			continue
+		}
+
+		for _, s := range env.Summaries {
+			b, ok := findSummary(base.Summaries, s.Benchmark, s.Unit)
+			if !ok {
+				c.New = append(c.New, Unmatched{Environment: env.Label(), Benchmark: s.Benchmark, Unit: s.Unit})
+				continue
+			}
+			if b.Mean == 0 {
+				continue
+			}
+
+			r := Result{
+				Environment:    env.Label(),
+				Benchmark:      s.Benchmark,
+				Unit:           s.Unit,
+				HigherIsBetter: s.HigherIsBetter,
+				Baseline:       b.Mean,
+				Current:        s.Mean,
+				Threshold:      t.For(s.Benchmark),
+			}
+			r.Delta, r.Regressed = Compare(b.Mean, s.Mean, s.HigherIsBetter, r.Threshold)
+
+			c.Results = append(c.Results, r)
+		}
+	}
+
+	// deleted, crashed or failed benchmarks must not pass the gate
+	for _, base := range baseline.Environments {
+		env, ok := findEnvironment(current, base.Label())
+		if !ok {
+			c.Missing = append(c.Missing, Unmatched{Environment: base.Label()})
+			continue
+		}
+
+		for _, b := range base.Summaries {
+			if _, ok := findSummary(env.Summaries, b.Benchmark, b.Unit); !ok {
+				c.Missing = append(c.Missing, Unmatched{Environment: base.Label(), Benchmark: b.Benchmark, Unit: b.Unit})
+			}
+		}
+	}
+
+	return c
+}
+
+// Err returns ErrRegression when any metric regressed, ErrMissing when
+// baseline benchmarks are missing from the run, nil otherwise
+func (c Comparison) Err() error {
+	if len(Regressions(c.Results)) > 0 {
+		return ErrRegression
+	}
+	if len(c.Missing) > 0 {
+		return ErrMissing
+	}
+	return nil
+}
+
+// Compare returns the relative change from `baseline` to `current` and
+// whether it's a slowdown beyond `threshold`, `baseline` can't be zero
+func Compare(baseline, current float64, higherIsBetter bool, threshold float64) (float64, bool) {
+
+	delta := (current - baseline) / baseline
+
+	// slowdowns are positive deltas, unless higher is better
+	worse := delta
+	if higherIsBetter {
+		worse = -worse
+	}
+
+	return delta, worse > threshold
+}
+
+// Regressions returns the results that regressed
+func Regressions(results []Result) []Result {
+	var regressed []Result
+	for _, r := range results {
+		if r.Regressed {
+			regressed = append(regressed, r)
+		}
+	}
+	return regressed
+}
+
+// WriteSummary writes the results as a table, followed by the
+// environments and benchmarks found on only one of the reports
+func WriteSummary(w io.Writer, c Comparison) error {
+
+	if len(c.Results) == 0 && len(c.Missing) == 0 && len(c.New) == 0 {
+		_, err := fmt.Fprintln(w, "no benchmarks matched the baseline")
+		return err
+	}
+
+	if len(c.Results) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "environment\tbenchmark\tbaseline\tcurrent\tchange\tthreshold\tstatus")
+
+		for _, r := range c.Results {
+			status := "ok"
+			if r.Regressed {
+				status = "REGRESSION"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s %s\t%s %s\t%+.2f%%\t%.2f%%\t%s\n",
+				r.Environment, r.Benchmark,
+				reporter.FormatValue(r.Baseline), r.Unit, reporter.FormatValue(r.Current), r.Unit,
+				r.Delta*100, r.Threshold*100, status)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := writeUnmatched(w, "missing from this run", c.Missing); err != nil {
+		return err
+	}
+	return writeUnmatched(w, "not on the baseline", c.New)
+}
+
+func writeUnmatched(w io.Writer, title string, unmatched []Unmatched) error {
+
+	if len(unmatched) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "\n%s:\n", title); err != nil {
+		return err
+	}
+	for _, u := range unmatched {
+		if _, err := fmt.Fprintf(w, "  %s\n", u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findEnvironment(rep reporter.Report, label string) (reporter.ReportData, bool) {
+	for _, env := range rep.Environments {
+		if env.Label() == label {
+			return env, true
+		}
+	}
+	return reporter.ReportData{}, false
+}
+
+func findSummary(summaries []reporter.Summary, benchmark, unit string) (reporter.Summary, bool) {
+	for _, s := range summaries {
+		if s.Benchmark == benchmark && s.Unit == unit {
+			return s, true
+		}
+	}
+	return reporter.Summary{}, false
+}
@@ -0,0 +1,129 @@
+package regression
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drish/ben/reporter"
+	"github.com/stretchr/testify/assert"
+)
+
+func summary(name, unit string, higher bool, mean float64) reporter.Summary {
+	s := reporter.Summary{Benchmark: name, Unit: unit, HigherIsBetter: higher}
+	s.Mean = mean
+	return s
+}
+
+func TestParseThreshold(t *testing.T) {
+	v, err := ParseThreshold("5%")
+	assert.Nil(t, err)
+	assert.Equal(t, v, 0.05)
+
+	v, err = ParseThreshold("2.5")
+	assert.Nil(t, err)
+	assert.Equal(t, v, 0.025)
+
+	_, err = ParseThreshold("five")
+	assert.EqualError(t, err, "invalid threshold: five")
+
+	_, err = ParseThreshold("-1%")
+	assert.EqualError(t, err, "threshold can't be negative: -1%")
+}
+
+func TestCheck(t *testing.T) {
+
+	baseline := reporter.Report{Environments: []reporter.ReportData{
+		{Image: "golang:1.9", Machine: "local", Summaries: []reporter.Summary{
+			summary("BenchmarkFib10", "ns/op", false, 400),
+			summary("BenchmarkFib20", "ns/op", false, 50000),
+			summary("BenchmarkFib10", "i/s", true, 1000),
+		}},
+		{Image: "golang:1.8", Machine: "local", Summaries: []reporter.Summary{
+			summary("BenchmarkFib10", "ns/op", false, 400),
+		}},
+	}}
+
+	current := reporter.Report{Environments: []reporter.ReportData{
+		{Image: "golang:1.9", Machine: "local", Summaries: []reporter.Summary{
+			summary("BenchmarkFib10", "ns/op", false, 440),
+			summary("BenchmarkFib20", "ns/op", false, 52000),
+			summary("BenchmarkFib10", "i/s", true, 900),
+			summary("BenchmarkFib30", "ns/op", false, 1),
+		}},
+		{Image: "golang:1.10", Machine: "local", Summaries: []reporter.Summary{
+			summary("BenchmarkFib10", "ns/op", false, 800),
+		}},
+	}}
+
+	t.Run("default threshold", func(t *testing.T) {
+		c := Check(baseline, current, Thresholds{Default: 0.05})
+		results := c.Results
+		assert.Equal(t, len(results), 3)
+
+		assert.Equal(t, results[0].Environment, "golang:1.9 (local)")
+		assert.InDelta(t, results[0].Delta, 0.1, 1e-9)
+		assert.Equal(t, results[0].Regressed, true)
+
+		assert.Equal(t, results[1].Regressed, false)
+
+		// throughput dropping is a regression
+		assert.InDelta(t, results[2].Delta, -0.1, 1e-9)
+		assert.Equal(t, results[2].Regressed, true)
+
+		assert.Equal(t, len(Regressions(results)), 2)
+		assert.Equal(t, c.Err(), ErrRegression)
+	})
+
+	t.Run("unmatched", func(t *testing.T) {
+		c := Check(baseline, current, Thresholds{Default: 0.05})
+		assert.Equal(t, c.Missing, []Unmatched{{Environment: "golang:1.8 (local)"}})
+		assert.Equal(t, c.New, []Unmatched{
+			{Environment: "golang:1.9 (local)", Benchmark: "BenchmarkFib30", Unit: "ns/op"},
+			{Environment: "golang:1.10 (local)"},
+		})
+	})
+
+	t.Run("missing benchmarks", func(t *testing.T) {
+		run := reporter.Report{Environments: []reporter.ReportData{
+			{Image: "golang:1.9", Machine: "local", Summaries: []reporter.Summary{
+				summary("BenchmarkFib10", "ns/op", false, 400),
+			}},
+			{Image: "golang:1.8", Machine: "local", Summaries: []reporter.Summary{
+				summary("BenchmarkFib10", "ns/op", false, 400),
+			}},
+		}}
+
+		c := Check(baseline, run, Thresholds{Default: 0.05})
+		assert.Equal(t, len(Regressions(c.Results)), 0)
+		assert.Equal(t, c.Missing, []Unmatched{
+			{Environment: "golang:1.9 (local)", Benchmark: "BenchmarkFib20", Unit: "ns/op"},
+			{Environment: "golang:1.9 (local)", Benchmark: "BenchmarkFib10", Unit: "i/s"},
+		})
+		assert.Equal(t, c.Err(), ErrMissing)
+
+		assert.Equal(t, Check(baseline, baseline, Thresholds{}).Err(), nil)
+	})
+
+	t.Run("per benchmark threshold", func(t *testing.T) {
+		results := Check(baseline, current, Thresholds{
+			Default:    0.05,
+			Benchmarks: map[string]float64{"BenchmarkFib10": 0.15, "BenchmarkFib20": 0.01},
+		}).Results
+		assert.Equal(t, results[0].Regressed, false)
+		assert.Equal(t, results[1].Regressed, true)
+		assert.Equal(t, results[2].Regressed, false)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, WriteSummary(&buf, Check(baseline, current, Thresholds{Default: 0.05})))
+		assert.Contains(t, buf.String(), "+10.00%")
+		assert.Contains(t, buf.String(), "REGRESSION")
+		assert.Contains(t, buf.String(), "\nmissing from this run:\n  golang:1.8 (local)\n")
+		assert.Contains(t, buf.String(), "\nnot on the baseline:\n  golang:1.9 (local) BenchmarkFib30 (ns/op)\n  golang:1.10 (local)\n")
+
+		buf.Reset()
+		assert.Nil(t, WriteSummary(&buf, Comparison{}))
+		assert.Equal(t, buf.String(), "no benchmarks matched the baseline\n")
+	})
+}
@@ -0,0 +1,115 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drish/ben/stats"
+)
+
+// Alpha is the significance level used when comparing environments
+const Alpha = 0.05
+
+// Comparison lines up a benchmark metric across all environments
+type Comparison struct {
+	Benchmark      string            `json:"benchmark"`
+	Unit           string            `json:"unit"`
+	HigherIsBetter bool              `json:"higherIsBetter"`
+	Entries        []ComparisonEntry `json:"entries"` // one entry per environment, in config order
+}
+
+// ComparisonEntry is the result of a benchmark metric on a single environment
+type ComparisonEntry struct {
+	Environment string  `json:"environment"`
+	Present     bool    `json:"present"`     // false if the benchmark didn't run on this environment
+	Baseline    bool    `json:"baseline"`    // true for the baseline environment
+	Mean        float64 `json:"mean"`        // mean across repetitions
+	Delta       float64 `json:"delta"`       // relative difference against the baseline mean, in percent
+	P           float64 `json:"p"`           // Mann-Whitney U p-value against the baseline samples
+	Significant bool    `json:"significant"` // P is below Alpha
+}
+
+// Label identifies the environment on reports, ie: golang:1.9 (local) or golang:1.9 (local) @ main
+func (d ReportData) Label() string {
+	// environments differing only by their variables are told apart
+	label := d.Image + " (" + d.Machine
+	if len(d.Env) > 0 {
+		label += ", " + strings.Join(d.Env, " ")
+	}
+	label += ")"
+	if d.Revision != "" {
+		label += " @ " + d.Revision
+	}
+	return label
+}
+
+// Compare lines up every benchmark metric across environments against the baseline environment
+func Compare(d []ReportData, baseline int) []Comparison {
+
+	if baseline < 0 || baseline >= len(d) {
+		return nil
+	}
+
+	var comparisons []Comparison
+
+	for _, base := range d[baseline].Summaries {
+		c := Comparison{
+			Benchmark:      base.Benchmark,
+			Unit:           base.Unit,
+			HigherIsBetter: base.HigherIsBetter,
+		}
+
+		for i, env := range d {
+			entry := ComparisonEntry{
+				Environment: env.Label(),
+				Baseline:    i == baseline,
+			}
+
+			if s, ok := findSummary(env.Summaries, base.Benchmark, base.Unit); ok {
+				entry.Present = true
+				entry.Mean = s.Mean
+
+				if !entry.Baseline {
+					if base.Mean != 0 {
+						entry.Delta = (s.Mean - base.Mean) / base.Mean * 100
+					}
+
+					_, p, err := stats.MannWhitneyU(base.Samples, s.Samples)
+					if err == nil {
+						entry.P = p
+						entry.Significant = p < Alpha
+					}
+				}
+			}
+
+			c.Entries = append(c.Entries, entry)
+		}
+
+		comparisons = append(comparisons, c)
+	}
+
+	return comparisons
+}
+
+func findSummary(summaries []Summary, benchmark, unit string) (Summary, bool) {
+	for _, s := range summaries {
+		if s.Benchmark == benchmark && s.Unit == unit {
+			return s, true
+		}
+	}
+	return Summary{}, false
+}
+
+// formats a comparison table cell, non significant differences are shown as `~`
+func formatEntry(e ComparisonEntry) string {
+	switch {
+	case !e.Present:
+		return "-"
+	case e.Baseline:
+		return FormatValue(e.Mean)
+	case e.Significant:
+		return fmt.Sprintf("%s (%+.2f%%, p=%.3f)", FormatValue(e.Mean), e.Delta, e.P)
+	default:
+		return fmt.Sprintf("%s (~, p=%.3f)", FormatValue(e.Mean), e.P)
+	}
+}
@@ -0,0 +1,80 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/drish/ben/parsers"
+	"github.com/stretchr/testify/assert"
+)
+
+func samples(name string, values ...float64) []Summary {
+	var b []parsers.Benchmark
+	for _, v := range values {
+		b = append(b, parsers.Benchmark{Name: name, Metrics: []parsers.Metric{{Value: v, Unit: "ns/op"}}})
+	}
+	return Summarize(b)
+}
+
+func TestCompare(t *testing.T) {
+
+	d := []ReportData{
+		{Image: "golang:1.8", Machine: "local", Summaries: samples("BenchmarkFib10", 400, 401, 402, 403)},
+		{Image: "golang:1.9", Machine: "local", Summaries: samples("BenchmarkFib10", 440, 441, 442, 443)},
+		{Image: "golang:1.10", Machine: "local", Summaries: samples("BenchmarkFib10", 399, 402, 401, 404)},
+		{Image: "golang:1.11", Machine: "local", Summaries: samples("BenchmarkFib20", 51959)},
+	}
+
+	t.Run("against first environment", func(t *testing.T) {
+		c := Compare(d, 0)
+		assert.Equal(t, len(c), 1)
+		assert.Equal(t, c[0].Benchmark, "BenchmarkFib10")
+		assert.Equal(t, len(c[0].Entries), 4)
+
+		base := c[0].Entries[0]
+		assert.Equal(t, base.Environment, "golang:1.8 (local)")
+		assert.Equal(t, base.Baseline, true)
+		assert.Equal(t, base.Mean, 401.5)
+
+		slower := c[0].Entries[1]
+		assert.Equal(t, slower.Present, true)
+		assert.InDelta(t, slower.Delta, 9.96, 0.01)
+		assert.Equal(t, slower.Significant, true)
+
+		same := c[0].Entries[2]
+		assert.Equal(t, same.Present, true)
+		assert.Equal(t, same.Significant, false)
+
+		missing := c[0].Entries[3]
+		assert.Equal(t, missing.Present, false)
+	})
+
+	t.Run("against another environment", func(t *testing.T) {
+		c := Compare(d, 3)
+		assert.Equal(t, len(c), 1)
+		assert.Equal(t, c[0].Benchmark, "BenchmarkFib20")
+		assert.Equal(t, c[0].Entries[3].Baseline, true)
+		assert.Equal(t, c[0].Entries[0].Present, false)
+	})
+
+	t.Run("invalid baseline", func(t *testing.T) {
+		assert.Nil(t, Compare(d, 4))
+	})
+}
+
+func TestFormatEntry(t *testing.T) {
+	assert.Equal(t, formatEntry(ComparisonEntry{}), "-")
+	assert.Equal(t, formatEntry(ComparisonEntry{Present: true, Baseline: true, Mean: 401.5}), "401.50")
+	assert.Equal(t, formatEntry(ComparisonEntry{Present: true, Mean: 441.5, Delta: 9.96, P: 0.029, Significant: true}), "441.50 (+9.96%, p=0.029)")
+	assert.Equal(t, formatEntry(ComparisonEntry{Present: true, Mean: 401.5, P: 0.886}), "401.50 (~, p=0.886)")
+}
+
+func TestReportData_Label(t *testing.T) {
+	d := ReportData{Image: "golang:1.9", Machine: "local"}
+	assert.Equal(t, d.Label(), "golang:1.9 (local)")
+
+	d.Revision = "main"
+	assert.Equal(t, d.Label(), "golang:1.9 (local) @ main")
+
+	d.Env = []string{"GOGC=off", "GOMAXPROCS=1"}
+	assert.Equal(t, d.Label(), "golang:1.9 (local, GOGC=off GOMAXPROCS=1) @ main")
+}
@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var csvHeader = []string{
+	"environment", "image", "machine", "benchmark", "unit", "higher_is_better",
+	"n", "mean", "median", "stddev", "min", "max", "ci_low", "ci_high",
+}
+
+// CSVReporter writes one row per environment, benchmark and unit
+type CSVReporter struct {
+	Output string
+}
+
+// OutputFile returns the report destination
+func (r *CSVReporter) OutputFile() string {
+	return r.Output
+}
+
+// Write writes the summaries of every environment to the output file
+func (r *CSVReporter) Write(rep Report) error {
+	return writeFile(r.Output, func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+
+		if err := cw.Write(csvHeader); err != nil {
+			return errors.Wrap(err, "failed writing csv report")
+		}
+
+		f := func(v float64) string {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+
+		for _, env := range rep.Environments {
+			for _, s := range env.Summaries {
+				row := []string{
+					env.Label(), env.Image, env.Machine, s.Benchmark, s.Unit, strconv.FormatBool(s.HigherIsBetter),
+					strconv.Itoa(s.N), f(s.Mean), f(s.Median), f(s.StdDev), f(s.Min), f(s.Max), f(s.CILow), f(s.CIHigh),
+				}
+				if err := cw.Write(row); err != nil {
+					return errors.Wrap(err, "failed writing csv report")
+				}
+			}
+		}
+
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return errors.Wrap(err, "failed writing csv report")
+		}
+		return nil
+	})
+}
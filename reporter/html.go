@@ -0,0 +1,262 @@
+package reporter
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// chart dimensions, in pixels
+const (
+	chartLabelWidth = 240
+	chartBarWidth   = 420
+	chartValueWidth = 120
+	chartRowHeight  = 28
+)
+
+// HTMLReporter writes a single self contained html file, with inline css and svg charts
+type HTMLReporter struct {
+	Output string
+}
+
+// a bar chart of a benchmark metric across environments
+type chart struct {
+	Benchmark string
+	Unit      string
+	Width     int
+	Height    int
+	Bars      []bar
+}
+
+// a single environment on a chart, coordinates are already scaled
+type bar struct {
+	Label    string
+	Value    string
+	Baseline bool
+
+	X      int // bar start
+	Y      int // bar top
+	Width  float64
+	TextY  int // label and value baseline
+	ValueX float64
+
+	// error bar, from ErrorX1 to ErrorX2 with caps from CapY1 to CapY2
+	HasError bool
+	ErrorX1  float64
+	ErrorX2  float64
+	ErrorY   int
+	CapY1    int
+	CapY2    int
+}
+
+// builds one chart per benchmark and unit, bars are in environment order
+func charts(rep Report) []chart {
+
+	type key struct{ benchmark, unit string }
+
+	var keys []key
+	seen := map[key]bool{}
+	for _, env := range rep.Environments {
+		for _, s := range env.Summaries {
+			k := key{s.Benchmark, s.Unit}
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	var result []chart
+	for _, k := range keys {
+
+		// bars are scaled against the largest value on the chart
+		max := 0.0
+		for _, env := range rep.Environments {
+			if s, ok := findSummary(env.Summaries, k.benchmark, k.unit); ok {
+				if s.Mean > max {
+					max = s.Mean
+				}
+				if s.CIHigh > max {
+					max = s.CIHigh
+				}
+			}
+		}
+
+		scale := func(v float64) float64 {
+			if max <= 0 || v < 0 {
+				return 0
+			}
+			return v / max * chartBarWidth
+		}
+
+		c := chart{
+			Benchmark: k.benchmark,
+			Unit:      k.unit,
+			Width:     chartLabelWidth + chartBarWidth + chartValueWidth,
+		}
+
+		for i, env := range rep.Environments {
+			s, ok := findSummary(env.Summaries, k.benchmark, k.unit)
+			if !ok {
+				continue
+			}
+
+			y := len(c.Bars) * chartRowHeight
+			middle := y + chartRowHeight/2
+
+			b := bar{
+				Label:    env.Label(),
+				Value:    FormatValue(s.Mean),
+				Baseline: i == rep.Baseline && len(rep.Environments) > 1,
+				X:        chartLabelWidth,
+				Y:        y + 4,
+				Width:    scale(s.Mean),
+				TextY:    middle + 4,
+			}
+			b.ValueX = float64(chartLabelWidth) + b.Width + 10
+
+			// error bars show the 95% confidence interval of the mean
+			if s.N > 1 {
+				b.HasError = true
+				b.ErrorX1 = float64(chartLabelWidth) + scale(s.CILow)
+				b.ErrorX2 = float64(chartLabelWidth) + scale(s.CIHigh)
+				b.ErrorY = middle
+				b.CapY1 = middle - 5
+				b.CapY2 = middle + 5
+
+				if b.ErrorX2+10 > b.ValueX {
+					b.ValueX = b.ErrorX2 + 10
+				}
+			}
+
+			c.Bars = append(c.Bars, b)
+		}
+
+		c.Height = len(c.Bars) * chartRowHeight
+		result = append(result, c)
+	}
+
+	return result
+}
+
+var htmlTmpl = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Benchmark results</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292e; max-width: 980px; margin: 2em auto; padding: 0 1em; }
+h1, h2, h3 { font-weight: 600; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #dfe2e5; padding: 4px 10px; text-align: left; }
+th { background: #f6f8fa; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; font-size: 12px; }
+details { margin: 1em 0; }
+summary { cursor: pointer; }
+.chart { margin: 1em 0 2em; }
+.chart text { font-size: 12px; fill: #24292e; }
+.chart rect { fill: #0366d6; }
+.chart rect.baseline { fill: #6a737d; }
+.chart line { stroke: #24292e; stroke-width: 1; }
+.meta { color: #586069; }
+.error { color: #cb2431; }
+</style>
+</head>
+<body>
+<h1>Benchmark results</h1>
+<p class="meta">ben {{.BenVersion}}{{if not .StartedAt.IsZero}}, {{.StartedAt.Format "2006-01-02 15:04:05 MST"}}{{end}}</p>
+{{if .Comparisons}}
+<h2>Comparison</h2>
+<p>Baseline: <em>{{(index .Environments .Baseline).Label}}</em>, differences with p &lt; 0.05 (Mann-Whitney U test) are shown as deltas, others as ~</p>
+<table>
+<tr><th>Benchmark</th><th>Unit</th>{{range .Environments}}<th>{{.Label}}</th>{{end}}</tr>
+{{range .Comparisons}}<tr><td>{{.Benchmark}}</td><td>{{.Unit}}</td>{{range .Entries}}<td class="num">{{entry .}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+{{with .Failures}}
+<h2>Failures</h2>
+<table>
+<tr><th>Environment</th><th>Phase</th><th>Error</th></tr>
+{{range .}}<tr><td>{{.Label}}</td><td>{{.Failure.Phase}}</td><td class="error">{{.Failure.Error}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with charts .}}
+<h2>Charts</h2>
+{{range .}}
+<h3>{{.Benchmark}} <small class="meta">({{.Unit}})</small></h3>
+<svg class="chart" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
+{{range .Bars}}<text x="0" y="{{.TextY}}">{{.Label}}</text>
+<rect{{if .Baseline}} class="baseline"{{end}} x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="20"></rect>
+{{if .HasError}}<line x1="{{.ErrorX1}}" y1="{{.ErrorY}}" x2="{{.ErrorX2}}" y2="{{.ErrorY}}"></line>
+<line x1="{{.ErrorX1}}" y1="{{.CapY1}}" x2="{{.ErrorX1}}" y2="{{.CapY2}}"></line>
+<line x1="{{.ErrorX2}}" y1="{{.CapY1}}" x2="{{.ErrorX2}}" y2="{{.CapY2}}"></line>
+{{end}}<text x="{{.ValueX}}" y="{{.TextY}}">{{.Value}}</text>
+{{end}}</svg>
+{{end}}
+{{end}}
+<h2>Environments</h2>
+{{range .Environments}}
+<h3>{{.Label}}</h3>
+<table>
+<tr><th>Machine</th><td>{{.Machine}}</td></tr>
+{{if .Revision}}<tr><th>Revision</th><td>{{.Revision}} ({{.Commit}})</td></tr>
+{{end}}{{with .Host}}<tr><th>OS / Arch</th><td>{{.OS}} / {{.Arch}}</td></tr>
+<tr><th>Kernel</th><td>{{.Kernel}}</td></tr>
+<tr><th>CPU</th><td>{{.CPU}}</td></tr>
+<tr><th>Toolchain</th><td>{{.Toolchain}}</td></tr>
+{{else}}<tr><th>Docker version</th><td>{{.V}}</td></tr>
+<tr><th>Docker API version</th><td>{{.APIV}}</td></tr>
+<tr><th>Docker Go version</th><td>{{.GoV}}</td></tr>
+<tr><th>OS / Arch</th><td>{{.Os}} / {{.Arch}}</td></tr>
+{{end}}<tr><th>Commands before benchmark</th><td><code>{{.Before}}</code></td></tr>
+<tr><th>Benchmark command</th><td><code>{{.Command}}</code></td></tr>
+{{if .Env}}<tr><th>Environment variables</th><td>{{range .Env}}<code>{{.}}</code> {{end}}</td></tr>
+{{end}}<tr><th>Repetitions</th><td>{{.Repetitions}} ({{.Warmup}} warmup)</td></tr>
+</table>
+{{if .Summaries}}
+<table>
+<tr><th>Benchmark</th><th>Unit</th><th>Runs</th><th>Mean</th><th>Median</th><th>StdDev</th><th>Min</th><th>Max</th><th>95% CI</th></tr>
+{{range .Summaries}}<tr><td>{{.Benchmark}}</td><td>{{.Unit}}</td><td class="num">{{.N}}</td><td class="num">{{format .Mean}}</td><td class="num">{{format .Median}}</td><td class="num">{{format .StdDev}}</td><td class="num">{{format .Min}}</td><td class="num">{{format .Max}}</td><td class="num">{{format .CILow}} - {{format .CIHigh}}</td></tr>
+{{end}}</table>
+{{end}}
+{{range .Errors}}<p class="error">{{.}}</p>
+{{end}}{{with .Failure}}<p class="error">Failed on {{.Phase}}: {{.Error}}</p>
+<details open><summary>Logs</summary>
+<pre>{{.Logs}}</pre>
+</details>
+{{end}}
+<details{{if not .Summaries}} open{{end}}><summary>Raw output</summary>
+<pre>{{.Results}}</pre>
+</details>
+{{end}}
+<p class="meta"><small>Generated by <a href="https://github.com/drish/ben">ben</a></small></p>
+</body>
+</html>
+`
+
+// OutputFile returns the report destination
+func (r *HTMLReporter) OutputFile() string {
+	return r.Output
+}
+
+// Write renders the html report to the output file
+func (r *HTMLReporter) Write(rep Report) error {
+
+	t, err := template.New("html").Funcs(template.FuncMap{
+		"format": FormatValue,
+		"entry":  formatEntry,
+		"charts": charts,
+	}).Parse(htmlTmpl)
+	if err != nil {
+		return errors.Wrap(err, "failed parsing html template")
+	}
+
+	return writeFile(r.Output, func(w io.Writer) error {
+		if err := t.Execute(w, rep); err != nil {
+			return errors.Wrap(err, "failed rendering html report")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package reporter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLReporter_Write(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ben")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	d := []ReportData{
+		{Image: "golang:1.8", Machine: "local", Results: "<raw>", Summaries: samples("BenchmarkFib10", 400, 401, 402, 403)},
+		{Image: "golang:1.9", Machine: "local", Summaries: samples("BenchmarkFib10", 440, 441, 442, 443)},
+	}
+
+	out := filepath.Join(dir, "benchmarks.html")
+	rep, err := New(out, "", "")
+	assert.Nil(t, err)
+	assert.IsType(t, &HTMLReporter{}, rep)
+	assert.Nil(t, rep.Write(NewReport(d, 0)))
+
+	b, _ := ioutil.ReadFile(out)
+	html := string(b)
+
+	assert.Equal(t, strings.Contains(html, "<svg class=\"chart\""), true)
+	assert.Equal(t, strings.Count(html, "<rect"), 2)
+	assert.Equal(t, strings.Contains(html, "&lt;raw&gt;"), true)
+
+	// no external resources
+	assert.Equal(t, strings.Contains(html, "<script"), false)
+	assert.Equal(t, strings.Contains(html, "<link"), false)
+}
+
+func TestCharts(t *testing.T) {
+
+	rep := NewReport([]ReportData{
+		{Image: "golang:1.8", Machine: "local", Summaries: samples("BenchmarkFib10", 400, 400, 400, 400)},
+		{Image: "golang:1.9", Machine: "local", Summaries: samples("BenchmarkFib10", 800)},
+	}, 0)
+
+	c := charts(rep)
+	assert.Equal(t, len(c), 1)
+	assert.Equal(t, c[0].Benchmark, "BenchmarkFib10")
+	assert.Equal(t, len(c[0].Bars), 2)
+
+	assert.Equal(t, c[0].Bars[0].Baseline, true)
+	assert.Equal(t, c[0].Bars[0].Width, float64(chartBarWidth)/2)
+	assert.Equal(t, c[0].Bars[0].HasError, true)
+
+	assert.Equal(t, c[0].Bars[1].Width, float64(chartBarWidth))
+	assert.Equal(t, c[0].Bars[1].HasError, false)
+}
@@ -0,0 +1,53 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// JSONReporter writes the full report as indented json
+type JSONReporter struct {
+	Output string
+}
+
+// OutputFile returns the report destination
+func (r *JSONReporter) OutputFile() string {
+	return r.Output
+}
+
+// Write encodes the report to the output file
+func (r *JSONReporter) Write(rep Report) error {
+	return writeFile(r.Output, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(rep); err != nil {
+			return errors.Wrap(err, "failed writing json report")
+		}
+		return nil
+	})
+}
+
+// ReadReport reads a report written by the json reporter
+func ReadReport(path string) (Report, error) {
+
+	var rep Report
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rep, errors.Wrap(err, "failed reading report")
+	}
+
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return rep, errors.Wrap(err, "failed parsing report")
+	}
+
+	if rep.SchemaVersion != SchemaVersion {
+		return rep, errors.Errorf("unsupported report schema version: %d", rep.SchemaVersion)
+	}
+
+	return rep, nil
+}
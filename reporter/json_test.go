@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONReporter_Write(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ben")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	d := []ReportData{
+		{Image: "golang:1.8", Machine: "local", V: "17.09.1-ce", Summaries: samples("BenchmarkFib10", 400, 401, 402, 403)},
+		{Image: "golang:1.9", Machine: "local", Summaries: samples("BenchmarkFib10", 440, 441, 442, 443)},
+	}
+
+	t.Run("picked from extension", func(t *testing.T) {
+		out := filepath.Join(dir, "results.json")
+
+		rep, err := New(out, "", "")
+		assert.Nil(t, err)
+		assert.IsType(t, &JSONReporter{}, rep)
+		assert.Nil(t, rep.Write(NewReport(d, 0)))
+
+		b, err := ioutil.ReadFile(out)
+		assert.Nil(t, err)
+
+		var decoded Report
+		assert.Nil(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, decoded.SchemaVersion, SchemaVersion)
+		assert.Equal(t, len(decoded.Environments), 2)
+		assert.Equal(t, decoded.Environments[0].V, "17.09.1-ce")
+		assert.Equal(t, decoded.Environments[1].Summaries[0].Mean, 441.5)
+		assert.Equal(t, decoded.Comparisons[0].Entries[1].Significant, true)
+	})
+
+	t.Run("explicit format", func(t *testing.T) {
+		out := filepath.Join(dir, "results.txt")
+
+		rep, err := New(out, FormatJSON, "")
+		assert.Nil(t, err)
+		assert.Nil(t, rep.Write(NewReport(d, 0)))
+
+		b, err := ioutil.ReadFile(out)
+		assert.Nil(t, err)
+		assert.Equal(t, json.Valid(b), true)
+	})
+}
+
+func TestReadReport(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ben")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "results.json")
+	rep := &JSONReporter{Output: out}
+	assert.Nil(t, rep.Write(NewReport([]ReportData{{Image: "golang:1.9", Machine: "local"}}, 0)))
+
+	read, err := ReadReport(out)
+	assert.Nil(t, err)
+	assert.Equal(t, read.Environments[0].Label(), "golang:1.9 (local)")
+
+	assert.Nil(t, ioutil.WriteFile(out, []byte(`{"schemaVersion": 99}`), 0644))
+	_, err = ReadReport(out)
+	assert.EqualError(t, err, "unsupported report schema version: 99")
+
+	_, err = ReadReport(filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+}
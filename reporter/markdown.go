@@ -0,0 +1,122 @@
+package reporter
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// MarkdownReporter writes the benchmark summary as a markdown file
+type MarkdownReporter struct {
+	Output string
+}
+
+// very simple markdown template for reporting
+var tmpl = `## Benchmark results
+{{if .Comparisons}}
+### Comparison
+
+Baseline: _{{(index .Environments .Baseline).Label}}_, differences with p < 0.05 (Mann-Whitney U test) are shown as deltas, others as ~
+
+| Benchmark | Unit |{{range .Environments}} {{.Label}} |{{end}}
+|-----------|------|{{range .Environments}}---|{{end}}
+{{range .Comparisons}}| {{.Benchmark}} | {{.Unit}} |{{range .Entries}} {{entry .}} |{{end}}
+{{end}}{{end}}{{with .Failures}}
+### Failures
+
+| Environment | Phase | Error |
+|-------------|-------|-------|
+{{range .}}| {{.Label}} | {{.Failure.Phase}} | {{.Failure.Error}} |
+{{end}}{{end}}
+{{range .Environments}}
+#### {{.Image}}
+
+**Machine**: _{{.Machine}}_
+{{if .Revision}}
+**Revision**: _{{.Revision}}_ ({{.Commit}})
+{{end}}
+{{with .Host}}**Host Info**:
+
+* OS: {{.OS}}
+* Kernel: {{.Kernel}}
+* CPU: {{.CPU}}
+* Arch: {{.Arch}}
+* Toolchain: {{.Toolchain}}
+{{else}}**Docker Info**:
+
+* Version: {{.V}}
+* API Version: {{.APIV}}
+* Go Version: {{.GoV}}
+* OS: {{.Os}}
+* Arch: {{.Arch}}
+{{end}}
+**Commands before benchmark**: _{{.Before}}_
+
+**Benchmark command**: _{{.Command}}_
+{{if .Env}}
+**Environment variables**: _{{range $i, $v := .Env}}{{if $i}}, {{end}}{{$v}}{{end}}_
+{{end}}{{with .Failure}}
+**Failed** on _{{.Phase}}_: {{.Error}}
+
+<details><summary>Logs</summary>
+
+~~~
+{{.Logs}}
+~~~
+
+</details>
+{{end}}{{if and (gt .Repetitions 1) .Summaries}}
+**Repetitions**: _{{.Repetitions}}_ ({{.Warmup}} warmup)
+
+| Benchmark | Unit | Runs | Mean | Median | StdDev | Min | Max | 95% CI |
+|-----------|------|------|------|--------|--------|-----|-----|--------|
+{{range .Summaries}}| {{.Benchmark}} | {{.Unit}} | {{.N}} | {{format .Mean}} | {{format .Median}} | {{format .StdDev}} | {{format .Min}} | {{format .Max}} | {{format .CILow}} - {{format .CIHigh}} |
+{{end}}{{end}}{{if .Benchmarks}}{{if le .Repetitions 1}}
+| Benchmark | Iterations | Results |
+|-----------|------------|---------|
+{{range .Benchmarks}}| {{.Name}} | {{if .Iterations}}{{.Iterations}}{{else}}-{{end}} | {{range $i, $m := .Metrics}}{{if $i}}, {{end}}{{$m}}{{end}} |
+{{end}}{{end}}
+<details><summary>Raw output</summary>
+
+~~~
+{{.Results}}
+~~~
+
+</details>
+{{else if not .Failure}}
+~~~
+{{.Results}}
+~~~
+{{end}}
+{{end}}
+
+<sub><sup>Generated by [ben](https://github.com/drish/ben)</sup></sub>
+`
+
+// template helpers, also available to user supplied templates
+var funcs = template.FuncMap{
+	"format": FormatValue,
+	"entry":  formatEntry,
+}
+
+// OutputFile returns the report destination
+func (r *MarkdownReporter) OutputFile() string {
+	return r.Output
+}
+
+// Write renders the markdown template to the output file
+func (r *MarkdownReporter) Write(rep Report) error {
+
+	t, err := template.New("markdown").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return errors.Wrap(err, "failed parsing markdown template")
+	}
+
+	return writeFile(r.Output, func(w io.Writer) error {
+		if err := t.Execute(w, rep); err != nil {
+			return errors.Wrap(err, "failed rendering markdown report")
+		}
+		return nil
+	})
+}
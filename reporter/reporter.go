@@ -1,86 +1,205 @@
 package reporter
 
 import (
-	"fmt"
+	"io"
 	"os"
-	"text/template"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drish/ben/parsers"
+	"github.com/pkg/errors"
 )
 
-type ReportData struct {
-	Image   string
-	Machine string
-	Command string
-	Results string
-	Before  string
+// SchemaVersion is the version of the machine readable report format,
+// it must be bumped on any incompatible change to Report
+const SchemaVersion = 1
+
+// supported output formats
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatHTML     = "html"
+	FormatTemplate = "template"
+)
 
-	// docker info
-	V    string
-	GoV  string
-	Arch string
-	Os   string
-	APIV string
-}
+// Formats lists every supported output format
+var Formats = []string{FormatMarkdown, FormatJSON, FormatCSV, FormatHTML, FormatTemplate}
 
-type Reporter struct {
-	OutputFile string
+// Reporter is the interface that defines how to write benchmark reports
+type Reporter interface {
+	Write(rep Report) error
+	OutputFile() string
 }
 
-// very simple markdown template for reporting
-var tmpl = `## Benchmark results
+type ReportData struct {
+	Image   string `json:"image"`
+	Machine string `json:"machine"`
+	Command string `json:"command"`
+	Results string `json:"results"`
+	Before  string `json:"before"`
+
+	// environment variables of the before and benchmark containers, ie: GOMAXPROCS=1,
+	// variables passed from the host are listed by name only
+	Env []string `json:"env,omitempty"`
+
+	// git revision benchmarked, blank when benchmarking the working directory
+	Revision string `json:"revision,omitempty"`
+	Commit   string `json:"commit,omitempty"`
 
-{{range .RepData}}
-#### {{.Image}}
+	// parsed benchmark results of every repetition
+	Benchmarks []parsers.Benchmark `json:"benchmarks"`
 
-**Machine**: _{{.Machine}}_
+	// statistics across repetitions
+	Repetitions int       `json:"repetitions"`
+	Warmup      int       `json:"warmup"`
+	Summaries   []Summary `json:"summaries"`
 
-**Docker Info**:
+	// timings
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
 
-* Version: {{.V}}
-* API Version: {{.APIV}}
-* Go Version: {{.GoV}}
-* OS: {{.Os}}
-* Arch: {{.Arch}}
+	// non fatal errors, ie: output that couldn't be parsed
+	Errors []string `json:"errors"`
 
-**Commands before benchmark**: _{{.Before}}_
+	// set when the environment failed to run
+	Failure *Failure `json:"failure,omitempty"`
 
-**Benchmark command**: _{{.Command}}_
+	// set instead of the docker info when running without docker
+	Host *HostInfo `json:"host,omitempty"`
 
-~~~
-{{.Results}}
-~~~
+	// docker info
+	V    string `json:"dockerVersion"`
+	GoV  string `json:"dockerGoVersion"`
+	Arch string `json:"dockerArch"`
+	Os   string `json:"dockerOs"`
+	APIV string `json:"dockerApiVersion"`
+}
+
+// HostInfo describes the host benchmarks ran on without docker
+type HostInfo struct {
+	OS        string `json:"os"`        // ie: Ubuntu 16.04.3 LTS
+	Kernel    string `json:"kernel"`    // ie: Linux 4.13.0-32-generic
+	CPU       string `json:"cpu"`       // cpu model name
+	Arch      string `json:"arch"`      // ie: amd64
+	Toolchain string `json:"toolchain"` // runtime version, ie: go version go1.9.2 linux/amd64
+}
 
-{{end}}
+// Failure describes why an environment failed to run
+type Failure struct {
+	Phase string `json:"phase"` // step that failed, ie: prepare image
+	Error string `json:"error"`
+	Logs  string `json:"logs"` // output captured until the failure
+}
 
-<sub><sup>Generated by [ben](https://github.com/drish/ben)</sup></sub>
-`
+// Report is the full benchmark run
+type Report struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	BenVersion    string       `json:"benVersion"`
+	StartedAt     time.Time    `json:"startedAt"`
+	FinishedAt    time.Time    `json:"finishedAt"`
+	Baseline      int          `json:"baseline"` // index of the environment others are compared against
+	Environments  []ReportData `json:"environments"`
+	Comparisons   []Comparison `json:"comparisons"`
+}
 
-// Creates a new reporter
-func NewReporter(outputFile string) *Reporter {
+// Failures returns the environments that failed to run
+func (r Report) Failures() []ReportData {
+	var failed []ReportData
+	for _, env := range r.Environments {
+		if env.Failure != nil {
+			failed = append(failed, env)
+		}
+	}
+	return failed
+}
+
+// NewReport creates the report of a run, comparing environments against the baseline
+func NewReport(d []ReportData, baseline int) Report {
+
+	// comparisons only make sense with more than one environment
+	var comparisons []Comparison
+	if len(d) > 1 {
+		comparisons = Compare(d, baseline)
+	}
+
+	return Report{
+		SchemaVersion: SchemaVersion,
+		Baseline:      baseline,
+		Environments:  d,
+		Comparisons:   comparisons,
+	}
+}
+
+// New creates a reporter for the output file, the format is picked from
+// the file extension when not set. `templateFile` is only used by the template format.
+func New(outputFile, format, templateFile string) (Reporter, error) {
 
 	if outputFile == "" {
 		outputFile = "benchmarks.md"
 	}
 
-	return &Reporter{
-		OutputFile: outputFile,
+	if format == "" {
+		format = FormatFromFile(outputFile)
+	}
+
+	switch format {
+	case FormatMarkdown:
+		return &MarkdownReporter{Output: outputFile}, nil
+	case FormatJSON:
+		return &JSONReporter{Output: outputFile}, nil
+	case FormatCSV:
+		return &CSVReporter{Output: outputFile}, nil
+	case FormatHTML:
+		return &HTMLReporter{Output: outputFile}, nil
+	case FormatTemplate:
+		return NewTemplateReporter(outputFile, templateFile)
+	default:
+		return nil, errors.Errorf("invalid output format: %s", format)
 	}
 }
 
-// Writes the benchmark sumary to fs as a markdown file
-func (r *Reporter) Run(d []ReportData) error {
+// ParseOutput splits an output flag value in the form of `[format:]file`
+func ParseOutput(s string) (file, format string) {
+	if i := strings.Index(s, ":"); i > 0 {
+		for _, f := range Formats {
+			if s[:i] == f {
+				return s[i+1:], f
+			}
+		}
+	}
+	return s, ""
+}
 
-	f, _ := os.Create(r.OutputFile)
-	defer f.Close()
+// FormatFromFile returns the output format matching the file extension
+func FormatFromFile(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatMarkdown
+	}
+}
+
+// creates the file at `path` and calls `write` with it,
+// returning rendering as well as fs errors
+func writeFile(path string, write func(w io.Writer) error) (err error) {
 
-	t := template.New("")
-	t, _ = t.Parse(tmpl)
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed creating report file")
+	}
 
-	t.Execute(f, struct {
-		RepData []ReportData
-	}{
-		RepData: d,
-	})
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "failed writing report file")
+		}
+	}()
 
-	fmt.Printf("\r  \033[36mwrote results to \033[m %s\n", r.OutputFile)
-	return nil
+	return write(f)
 }
@@ -0,0 +1,158 @@
+package reporter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporter_FormatFromFile(t *testing.T) {
+	assert.Equal(t, FormatFromFile("results.json"), FormatJSON)
+	assert.Equal(t, FormatFromFile("RESULTS.JSON"), FormatJSON)
+	assert.Equal(t, FormatFromFile("results.csv"), FormatCSV)
+	assert.Equal(t, FormatFromFile("benchmarks.md"), FormatMarkdown)
+	assert.Equal(t, FormatFromFile("benchmarks"), FormatMarkdown)
+}
+
+func TestReporter_ParseOutput(t *testing.T) {
+
+	file, format := ParseOutput("results.json")
+	assert.Equal(t, file, "results.json")
+	assert.Equal(t, format, "")
+
+	file, format = ParseOutput("csv:results.txt")
+	assert.Equal(t, file, "results.txt")
+	assert.Equal(t, format, FormatCSV)
+
+	file, format = ParseOutput("c:results.md")
+	assert.Equal(t, file, "c:results.md")
+	assert.Equal(t, format, "")
+}
+
+func TestReporter_New(t *testing.T) {
+
+	t.Run("default output", func(t *testing.T) {
+		rep, err := New("", "", "")
+		assert.Nil(t, err)
+		assert.IsType(t, &MarkdownReporter{}, rep)
+		assert.Equal(t, rep.OutputFile(), "benchmarks.md")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := New("results.xml", "xml", "")
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "invalid output format: xml")
+	})
+
+	t.Run("template without template file", func(t *testing.T) {
+		_, err := New("results.txt", FormatTemplate, "")
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "template output requires a template file")
+	})
+}
+
+func TestReporter_Write(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ben")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	d := []ReportData{
+		{Image: "golang:1.8", Machine: "local", Summaries: samples("BenchmarkFib10", 400, 401, 402, 403)},
+		{Image: "golang:1.9", Machine: "local", Summaries: samples("BenchmarkFib10", 440, 441, 442, 443)},
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		out := filepath.Join(dir, "benchmarks.md")
+		rep, _ := New(out, "", "")
+		assert.Nil(t, rep.Write(NewReport(d, 0)))
+
+		b, _ := ioutil.ReadFile(out)
+		assert.Equal(t, strings.Contains(string(b), "| BenchmarkFib10 | ns/op | 401.50 | 441.50 (+9.96%, p=0.029) |"), true)
+	})
+
+	t.Run("markdown failures", func(t *testing.T) {
+		failed := append(d, ReportData{
+			Image:   "jruby:9.1",
+			Machine: "local",
+			Failure: &Failure{Phase: "prepare image", Error: "running 'before' commands failed", Logs: "gem not found"},
+		})
+
+		out := filepath.Join(dir, "failures.md")
+		rep, _ := New(out, "", "")
+		assert.Nil(t, rep.Write(NewReport(failed, 0)))
+
+		b, _ := ioutil.ReadFile(out)
+		assert.Equal(t, strings.Contains(string(b), "| jruby:9.1 (local) | prepare image | running 'before' commands failed |"), true)
+		assert.Equal(t, strings.Contains(string(b), "gem not found"), true)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		out := filepath.Join(dir, "results.csv")
+		rep, _ := New(out, "", "")
+		assert.Nil(t, rep.Write(NewReport(d, 0)))
+
+		b, _ := ioutil.ReadFile(out)
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		assert.Equal(t, len(lines), 3)
+		assert.Equal(t, lines[0], "environment,image,machine,benchmark,unit,higher_is_better,n,mean,median,stddev,min,max,ci_low,ci_high")
+		assert.Equal(t, strings.HasPrefix(lines[1], "golang:1.8 (local),golang:1.8,local,BenchmarkFib10,ns/op,false,4,401.5,401.5,"), true)
+	})
+
+	t.Run("template", func(t *testing.T) {
+		tmplFile := filepath.Join(dir, "summary.tmpl")
+		ioutil.WriteFile(tmplFile, []byte(`{{range .Environments}}{{.Label}}:{{range .Summaries}} {{format .Mean}}{{end}}
+{{end}}`), 0644)
+
+		out := filepath.Join(dir, "summary.txt")
+		rep, err := New(out, FormatTemplate, tmplFile)
+		assert.Nil(t, err)
+		assert.Nil(t, rep.Write(NewReport(d, 0)))
+
+		b, _ := ioutil.ReadFile(out)
+		assert.Equal(t, string(b), "golang:1.8 (local): 401.50\ngolang:1.9 (local): 441.50\n")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		tmplFile := filepath.Join(dir, "invalid.tmpl")
+		ioutil.WriteFile(tmplFile, []byte(`{{range .Environments}}`), 0644)
+
+		_, err := New(filepath.Join(dir, "out.txt"), FormatTemplate, tmplFile)
+		assert.NotNil(t, err)
+		assert.Equal(t, strings.HasPrefix(err.Error(), "failed parsing template"), true)
+	})
+
+	t.Run("template execution error", func(t *testing.T) {
+		tmplFile := filepath.Join(dir, "missing.tmpl")
+		ioutil.WriteFile(tmplFile, []byte(`{{.Missing}}`), 0644)
+
+		rep, err := New(filepath.Join(dir, "out.txt"), FormatTemplate, tmplFile)
+		assert.Nil(t, err)
+
+		err = rep.Write(NewReport(d, 0))
+		assert.NotNil(t, err)
+		assert.Equal(t, strings.HasPrefix(err.Error(), "failed rendering template"), true)
+	})
+
+	t.Run("unwritable output", func(t *testing.T) {
+		rep, _ := New(filepath.Join(dir, "missing", "benchmarks.md"), "", "")
+		err := rep.Write(NewReport(d, 0))
+		assert.NotNil(t, err)
+		assert.Equal(t, strings.HasPrefix(err.Error(), "failed creating report file"), true)
+	})
+}
+
+func TestReport_Failures(t *testing.T) {
+	rep := NewReport([]ReportData{
+		{Image: "golang:1.9", Machine: "local"},
+		{Image: "jruby:9.1", Machine: "local", Failure: &Failure{Phase: "benchmark"}},
+	}, 0)
+
+	failed := rep.Failures()
+	assert.Equal(t, len(failed), 1)
+	assert.Equal(t, failed[0].Image, "jruby:9.1")
+}
@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/drish/ben/parsers"
+	"github.com/drish/ben/stats"
+)
+
+// Summary holds the statistics of a benchmark metric across repetitions
+type Summary struct {
+	Benchmark      string    `json:"benchmark"`
+	Unit           string    `json:"unit"`
+	HigherIsBetter bool      `json:"higherIsBetter"`
+	Samples        []float64 `json:"samples"`
+	stats.Summary
+}
+
+// Summarize groups the parsed benchmarks of every repetition by benchmark name and unit
+func Summarize(benchmarks []parsers.Benchmark) []Summary {
+
+	var summaries []Summary
+	index := map[string]int{}
+
+	for _, b := range benchmarks {
+		for _, m := range b.Metrics {
+			key := b.Name + "\x00" + m.Unit
+
+			i, ok := index[key]
+			if !ok {
+				i = len(summaries)
+				index[key] = i
+				summaries = append(summaries, Summary{
+					Benchmark:      b.Name,
+					Unit:           m.Unit,
+					HigherIsBetter: m.HigherIsBetter,
+				})
+			}
+			summaries[i].Samples = append(summaries[i].Samples, m.Value)
+		}
+	}
+
+	for i := range summaries {
+		summaries[i].Summary = stats.Summarize(summaries[i].Samples)
+	}
+
+	return summaries
+}
+
+// FormatValue formats values with a precision that depends on their magnitude
+func FormatValue(v float64) string {
+	switch {
+	case math.Abs(v) >= 1000:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case math.Abs(v) >= 1:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return strconv.FormatFloat(v, 'g', 4, 64)
+	}
+}
@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/drish/ben/parsers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+
+	benchmarks := []parsers.Benchmark{
+		{Name: "BenchmarkFib10", Metrics: []parsers.Metric{{Value: 410, Unit: "ns/op"}, {Value: 0, Unit: "B/op"}}},
+		{Name: "BenchmarkFib20", Metrics: []parsers.Metric{{Value: 51959, Unit: "ns/op"}}},
+		{Name: "BenchmarkFib10", Metrics: []parsers.Metric{{Value: 420, Unit: "ns/op"}, {Value: 0, Unit: "B/op"}}},
+	}
+
+	s := Summarize(benchmarks)
+	assert.Equal(t, len(s), 3)
+
+	assert.Equal(t, s[0].Benchmark, "BenchmarkFib10")
+	assert.Equal(t, s[0].Unit, "ns/op")
+	assert.Equal(t, s[0].Samples, []float64{410, 420})
+	assert.Equal(t, s[0].N, 2)
+	assert.Equal(t, s[0].Mean, 415.0)
+
+	assert.Equal(t, s[1].Unit, "B/op")
+	assert.Equal(t, s[2].Benchmark, "BenchmarkFib20")
+	assert.Equal(t, s[2].N, 1)
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, FormatValue(51959.333), "51959")
+	assert.Equal(t, FormatValue(413.3333), "413.33")
+	assert.Equal(t, FormatValue(0.000123456), "0.0001235")
+}
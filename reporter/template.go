@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"io"
+	"path/filepath"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// TemplateReporter renders a user supplied text/template file,
+// the template is executed with the Report
+type TemplateReporter struct {
+	Output   string
+	Template *template.Template
+}
+
+// NewTemplateReporter parses the template file, failing early on invalid templates
+func NewTemplateReporter(outputFile, templateFile string) (*TemplateReporter, error) {
+
+	if templateFile == "" {
+		return nil, errors.New("template output requires a template file")
+	}
+
+	t, err := template.New(filepath.Base(templateFile)).Funcs(funcs).ParseFiles(templateFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parsing template")
+	}
+
+	return &TemplateReporter{
+		Output:   outputFile,
+		Template: t,
+	}, nil
+}
+
+// OutputFile returns the report destination
+func (r *TemplateReporter) OutputFile() string {
+	return r.Output
+}
+
+// Write renders the template to the output file
+func (r *TemplateReporter) Write(rep Report) error {
+	return writeFile(r.Output, func(w io.Writer) error {
+		if err := r.Template.Execute(w, rep); err != nil {
+			return errors.Wrap(err, "failed rendering template")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package ben
+
+import (
+	"fmt"
+
+	"github.com/drish/ben/git"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+// source is the project directory copied into benchmark images,
+// either the working directory or a revision checked out on a worktree
+type source struct {
+	Revision string
+	Commit   string
+	Dir      string
+}
+
+// checks out every revision on a temporary worktree,
+// without revisions the working directory is benchmarked
+func checkout(revisions []string) ([]source, error) {
+
+	if len(revisions) == 0 {
+		return []source{{Dir: "."}}, nil
+	}
+
+	var sources []source
+	for _, rev := range revisions {
+		commit, err := git.Resolve(".", rev)
+		if err != nil {
+			removeWorktrees(sources)
+			return nil, errors.Wrapf(err, "invalid revision %s", rev)
+		}
+
+		dir, err := git.AddWorktree(".", commit)
+		if err != nil {
+			removeWorktrees(sources)
+			return nil, errors.Wrapf(err, "failed checking out %s", rev)
+		}
+
+		fmt.Printf("  \033[36mchecked out revision \033[m%s (%s)\n", rev, commit[:8])
+		sources = append(sources, source{Revision: rev, Commit: commit, Dir: dir})
+	}
+
+	fmt.Println()
+	return sources, nil
+}
+
+// removes the worktrees created by checkout
+func removeWorktrees(sources []source) {
+	for _, s := range sources {
+		if s.Revision == "" {
+			continue
+		}
+		if err := git.RemoveWorktree(".", s.Dir); err != nil {
+			fmt.Printf("  \033[36mremoving worktree \033[m%s %s\n", s.Dir, color.RedString("failed !"))
+		}
+	}
+}
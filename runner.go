@@ -1,115 +1,412 @@
 package ben
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/drish/ben/builders"
 	"github.com/drish/ben/config"
+	"github.com/drish/ben/git"
+	"github.com/drish/ben/history"
+	"github.com/drish/ben/parsers"
+	"github.com/drish/ben/regression"
 	"github.com/drish/ben/reporter"
 	"github.com/drish/ben/utils"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
 )
 
+// ErrInterrupted is returned when the run is canceled, after
+// cleaning up and writing the partial report
+var ErrInterrupted = errors.New("benchmarks interrupted, partial results written")
+
+// time given to builders to remove what they created, regardless of cancellation
+const cleanupTimeout = 5 * time.Minute
+
 // Runner defines the top-level runner struct
 type Runner struct {
 	config *config.Config
 }
 
-// Run is the entrypoint method
-func (r *Runner) Run(output string, display bool) error {
+// Options holds the command line options of a run
+type Options struct {
+	Reporters  []reporter.Reporter // report outputs
+	Display    bool                // display benchmark results to stdout
+	HistoryDir string              // history store directory, history is disabled when blank
+
+	// report the run is checked for regressions against, skipped when nil
+	Baseline  *reporter.Report
+	Threshold string // allowed slowdown, overrides the config default
+
+	// git revisions to benchmark, overrides the config revisions
+	Revisions []string
+
+	// maximum number of environments benchmarked at once, default to 1
+	Parallel      int
+	ParallelLocal bool // allows local environments to run at once
+
+	// keeps benchmarking the remaining environments when one fails
+	KeepGoing bool
+}
+
+// Run is the entrypoint method, canceling `ctx` stops the running environments,
+// cleans them up and writes a partial report
+func (r *Runner) Run(ctx context.Context, opts Options) error {
 
 	utils.Welcome()
 
-	var reports []reporter.ReportData
+	startedAt := time.Now()
 
-	for _, env := range r.config.Environments {
+	revisions := opts.Revisions
+	if len(revisions) == 0 {
+		revisions = r.config.Revisions
+	}
+
+	// every revision is checked out once and benchmarked on every environment
+	sources, err := checkout(revisions)
+	if err != nil {
+		return err
+	}
+	defer removeWorktrees(sources)
 
-		// set version as latest if no set
-		if env.Version == "" {
-			env.Version = "latest"
+	// resources created by the run are labelled, so leftovers can be cleaned
+	runID := newRunID()
+
+	var jobs []job
+	for i, env := range r.config.Environments {
+
+		env, err := prepareEnvironment(env)
+		if err != nil {
+			return err
 		}
 
-		// set default command or exit
-		if env.Command == "" {
-			defaultCmd := config.DefaultCommand(env.Runtime)
-			if defaultCmd == "" {
-				return errors.New("command can not be blank")
-			}
-			env.Command = defaultCmd
+		for _, src := range sources {
+			jobs = append(jobs, job{env: env, src: src, labels: builders.Labels(runID, i, Version)})
 		}
+	}
 
-		before := utils.PrepareBeforeCommands(env.Before)
-		image := utils.PrepareImage(env.Runtime, env.Version)
+	reports, err := r.runJobs(ctx, jobs, opts)
+	if err != nil {
+		return err
+	}
 
-		command := utils.PrepareCommand(env.Command)
+	// the first revision of the baseline environment is the baseline
+	baseline := r.config.Baseline * len(sources)
 
-		if env.Machine == "local" {
-			builder := &builders.LocalBuilder{
-				Image:   image,
-				Before:  before,
-				Command: command,
-			}
-			rp, err := r.BuildRuntime(builder, output, display)
-			if err != nil {
-				return err
-			}
-			reports = append(reports, rp)
-		} else {
-			builder := &builders.HyperBuilder{
-				Image:     image,
-				Before:    before,
-				HyperSize: strings.Split(env.Machine, "-")[1],
-				Command:   command,
-			}
-			rp, err := r.BuildRuntime(builder, output, display)
-			if err != nil {
-				return err
+	// interrupted runs only report the environments that started
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		reports, baseline = started(reports, baseline)
+	}
+
+	report := reporter.NewReport(reports, baseline)
+	report.BenVersion = Version
+	report.StartedAt = startedAt
+	report.FinishedAt = time.Now()
+
+	// generate reports, every output is attempted even if one fails
+	var failed error
+	for _, rep := range opts.Reporters {
+		if err := rep.Write(report); err != nil {
+			fmt.Printf("\r  \033[36mwriting results to \033[m %s %s\n", rep.OutputFile(), color.RedString("failed !"))
+			if failed == nil {
+				failed = errors.Wrapf(err, "failed writing %s", rep.OutputFile())
 			}
-			reports = append(reports, rp)
+			continue
+		}
+		fmt.Printf("\r  \033[36mwrote results to \033[m %s\n", rep.OutputFile())
+	}
+
+	// partial runs aren't recorded nor checked for regressions
+	if interrupted {
+		return ErrInterrupted
+	}
+
+	if opts.HistoryDir != "" {
+		if err := r.record(opts.HistoryDir, report); err != nil && failed == nil {
+			failed = err
+		}
+	}
+
+	// failed environments make the run fail once reports are written
+	if n := len(report.Failures()); n > 0 && failed == nil {
+		failed = errors.Errorf("%d of %d environments failed", n, len(report.Environments))
+	}
+
+	if opts.Baseline != nil {
+		c := regression.Check(*opts.Baseline, report, r.thresholds(opts.Threshold))
+
+		fmt.Printf("\n\r  \033[36mregressions against baseline \033[m\n\n")
+		if err := regression.WriteSummary(os.Stdout, c); err != nil && failed == nil {
+			failed = err
 		}
+		fmt.Println()
+
+		if err := c.Err(); err != nil && failed == nil {
+			failed = err
+		}
+	}
+
+	return failed
+}
+
+// filters out reports of jobs that never started, returning the new baseline index,
+// the first report when the baseline didn't start
+func started(reports []reporter.ReportData, baseline int) ([]reporter.ReportData, int) {
+
+	var filtered []reporter.ReportData
+	index := 0
+
+	for i, rp := range reports {
+		if rp.StartedAt.IsZero() {
+			continue
+		}
+		if i == baseline {
+			index = len(filtered)
+		}
+		filtered = append(filtered, rp)
+	}
+
+	return filtered, index
+}
+
+// builds the regression thresholds, benchmark thresholds take precedence
+// over the command line threshold, which overrides the config default
+func (r *Runner) thresholds(threshold string) regression.Thresholds {
+
+	t := regression.Thresholds{
+		Default:    regression.DefaultThreshold,
+		Benchmarks: map[string]float64{},
+	}
+
+	if threshold == "" {
+		threshold = r.config.Threshold
+	}
+
+	// thresholds are parsed on validation
+	if threshold != "" {
+		t.Default, _ = regression.ParseThreshold(threshold)
+	}
+	for name, v := range r.config.Thresholds {
+		t.Benchmarks[name], _ = regression.ParseThreshold(v)
 	}
 
-	// generate reports
-	rep := reporter.NewReporter(output)
-	if err := rep.Run(reports); err != nil {
+	return t
+}
+
+// sets the environment defaults
+func prepareEnvironment(env config.Environment) (config.Environment, error) {
+
+	// set version as latest if no set
+	if env.Version == "" {
+		env.Version = "latest"
+	}
+
+	// set default command or exit
+	if env.Command == "" {
+		defaultCmd := config.DefaultCommand(env.Runtime)
+		if defaultCmd == "" {
+			return env, errors.New("command can not be blank")
+		}
+		env.Command = defaultCmd
+	}
+
+	// run the benchmark once if not set
+	if env.Repetitions == 0 {
+		env.Repetitions = 1
+	}
+
+	return env, nil
+}
+
+// creates the builder of the environment machine, copying `source` into the benchmark image
+func newBuilder(env config.Environment, source string, labels map[string]string, out io.Writer) (builders.RuntimeBuilder, error) {
+	return builders.New(builders.Options{
+		Runtime: env.Runtime,
+		Image:   utils.PrepareImage(env.Runtime, env.Version),
+		Before:  utils.PrepareBeforeCommands(env.Before),
+		Command: utils.PrepareCommand(env.Command),
+		Env:     utils.PrepareEnv(env.Env, env.PassEnv),
+		Source:  source,
+		Machine: env.Machine,
+		Limits:  limits(env),
+		Options: env.Options,
+		Labels:  labels,
+		Output:  out,
+	})
+}
+
+// inline environment limits, taking precedence over the machine profile ones
+func limits(env config.Environment) builders.Limits {
+	l := builders.Limits{CPUs: env.CPUs, Cpuset: env.Cpuset}
+
+	// memory is parsed on config validation
+	if env.Memory != "" {
+		l.Memory, _ = builders.ParseMemory(env.Memory)
+	}
+	return l
+}
+
+// identifies the resources created by a run
+func newRunID() string {
+	return strings.ToLower(utils.RandString(12))
+}
+
+// appends the run to the history store
+func (r *Runner) record(dir string, report reporter.Report) error {
+
+	store, err := history.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	// runs outside git repositories are stored without a commit
+	commit, _ := git.Head(".")
+
+	entry := &history.Entry{
+		Timestamp:  report.StartedAt,
+		Commit:     commit,
+		ConfigHash: r.config.Hash(),
+		Report:     report,
+	}
+	if err := store.Append(entry); err != nil {
 		return err
 	}
+
+	fmt.Printf("\r  \033[36mrecorded run on history \033[m %s\n", entry.ID)
 	return nil
 }
 
-// BuildRuntime builds the appropriate runtime
-func (r *Runner) BuildRuntime(b builders.RuntimeBuilder, output string, display bool) (reporter.ReportData, error) {
+// BuildRuntime builds the appropriate runtime and runs the benchmark
+// `env.Warmup` + `env.Repetitions` times on the same benchmark image
+func (r *Runner) BuildRuntime(ctx context.Context, b builders.RuntimeBuilder, env config.Environment, display bool, out io.Writer) (reporter.ReportData, error) {
+
+	startedAt := time.Now()
+
+	// failed environments are cleaned up, and still report
+	// what they are and where they failed
+	failed := func(phase string, err error) (reporter.ReportData, error) {
+		if phase != "cleanup" {
+			cleanup(b)
+		}
+
+		rp := b.Report()
+		rp.Failure = &reporter.Failure{Phase: phase, Error: err.Error(), Logs: rp.Results}
+		rp.Results = ""
+		rp.Env = utils.ReportEnv(env.Env, env.PassEnv)
+		rp.Repetitions = env.Repetitions
+		rp.Warmup = env.Warmup
+		rp.StartedAt = startedAt
+		rp.FinishedAt = time.Now()
+		return rp, err
+	}
 
 	// sets up necessary variables
-	if err := b.Init(); err != nil {
-		return reporter.ReportData{}, err
+	if err := b.Init(ctx); err != nil {
+		return failed("init", err)
 	}
 
 	// pulls base image, run before commands and create benchmark image
-	if err := b.PrepareImage(); err != nil {
-		return reporter.ReportData{}, err
+	if err := b.PrepareImage(ctx); err != nil {
+		return failed("prepare image", err)
 	}
 
-	if err := b.SetupContainer(); err != nil {
-		return reporter.ReportData{}, err
-	}
+	var outputs, errs []string
+	var benchmarks []parsers.Benchmark
+
+	runs := env.Warmup + env.Repetitions
+	for i := 0; i < runs; i++ {
+
+		if err := b.SetupContainer(ctx); err != nil {
+			return failed("setup container", err)
+		}
+
+		if err := b.Benchmark(ctx); err != nil {
+			return failed("benchmark", err)
+		}
+
+		// the last container is removed along with the image on cleanup
+		if i < runs-1 {
+			if err := b.RemoveContainer(ctx); err != nil {
+				return failed("remove container", err)
+			}
+			fmt.Fprintln(out)
+		}
+
+		// warmup results are discarded
+		if i < env.Warmup {
+			continue
+		}
 
-	if err := b.Benchmark(); err != nil {
-		return reporter.ReportData{}, err
+		results := b.Report().Results
+		outputs = append(outputs, results)
+
+		// unparseable output is kept as raw results only
+		if p, ok := r.parser(env); ok {
+			parsed, err := p.Parse(results)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("run %d: %s", len(outputs), err))
+			}
+			benchmarks = append(benchmarks, parsed...)
+		}
 	}
 
-	if err := b.Cleanup(); err != nil {
-		return reporter.ReportData{}, err
+	if err := cleanup(b); err != nil {
+		return failed("cleanup", err)
 	}
 
 	if display {
 		b.Display()
 	} else {
-		fmt.Println()
+		fmt.Fprintln(out)
+	}
+
+	rp := b.Report()
+	rp.Results = strings.Join(outputs, "\n")
+	rp.Benchmarks = benchmarks
+	rp.Repetitions = env.Repetitions
+	rp.Warmup = env.Warmup
+	rp.Summaries = reporter.Summarize(benchmarks)
+	rp.Env = utils.ReportEnv(env.Env, env.PassEnv)
+	rp.Errors = errs
+	rp.StartedAt = startedAt
+	rp.FinishedAt = time.Now()
+
+	return rp, nil
+}
+
+// cleans up the builder with its own deadline, so interrupted runs are cleaned up as well
+func cleanup(b builders.RuntimeBuilder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	return b.Cleanup(ctx)
+}
+
+// picks the user defined metrics, the configured parser or the runtime's default one
+func (r *Runner) parser(env config.Environment) (parsers.Parser, bool) {
+	if len(env.Metrics) > 0 {
+		var rules []parsers.RegexpRule
+		for _, m := range env.Metrics {
+			rules = append(rules, parsers.RegexpRule{
+				Name: m.Name,
+				// expressions are compiled on config validation
+				Regexp:         regexp.MustCompile(m.Regexp),
+				Unit:           m.Unit,
+				HigherIsBetter: m.Better == "higher",
+			})
+		}
+		return parsers.NewRegexpParser(rules), true
 	}
 
-	return b.Report(), nil
+	if env.Parser != "" {
+		return parsers.Lookup(env.Parser)
+	}
+	return parsers.ForRuntime(env.Runtime)
 }
 
 // New is the Runner initializer
@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSampleSize is returned when one of the samples is empty
+var ErrSampleSize = errors.New("samples can't be empty")
+
+// above this many combined samples the normal approximation is used
+const exactLimit = 50
+
+// MannWhitneyU runs a two-sided Mann-Whitney U test on the samples,
+// returning the U statistic and the p-value for the null hypothesis that
+// both samples come from the same distribution.
+func MannWhitneyU(x, y []float64) (float64, float64, error) {
+
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 0, 0, ErrSampleSize
+	}
+
+	ranks, ties := rank(x, y)
+
+	r1 := 0.0
+	for _, r := range ranks[:n1] {
+		r1 += r
+	}
+
+	u1 := r1 - float64(n1*(n1+1))/2
+	u2 := float64(n1*n2) - u1
+	u := math.Min(u1, u2)
+
+	// exact distribution is only valid without ties
+	if len(ties) == 0 && n1+n2 <= exactLimit {
+		return u, exactP(n1, n2, int(u)), nil
+	}
+	return u, normalP(n1, n2, u, ties), nil
+}
+
+// ranks both samples together, tied values get the average of their ranks.
+// returns the ranks in x then y order and the size of each group of ties.
+func rank(x, y []float64) ([]float64, []int) {
+
+	type value struct {
+		v float64
+		i int
+	}
+
+	all := make([]value, 0, len(x)+len(y))
+	for i, v := range x {
+		all = append(all, value{v, i})
+	}
+	for i, v := range y {
+		all = append(all, value{v, len(x) + i})
+	}
+	sort.Slice(all, func(a, b int) bool { return all[a].v < all[b].v })
+
+	ranks := make([]float64, len(all))
+	var ties []int
+
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+
+		// average of ranks i+1..j
+		r := float64(i+1+j) / 2
+		for k := i; k < j; k++ {
+			ranks[all[k].i] = r
+		}
+
+		if j-i > 1 {
+			ties = append(ties, j-i)
+		}
+		i = j
+	}
+
+	return ranks, ties
+}
+
+// two-sided p-value from the exact distribution of U
+func exactP(n1, n2, u int) float64 {
+
+	// counts[m][n][k] is the number of arrangements of m x's and n y's with U = k,
+	// only the current and previous rows of m are kept
+	prev := make([][]float64, n2+1)
+	for n := range prev {
+		prev[n] = []float64{1}
+	}
+
+	for m := 1; m <= n1; m++ {
+		cur := make([][]float64, n2+1)
+		cur[0] = []float64{1}
+		for n := 1; n <= n2; n++ {
+			cur[n] = make([]float64, m*n+1)
+			for k := range cur[n] {
+				// largest value is an x, it's greater than all n y's
+				if k-n >= 0 && k-n < len(prev[n]) {
+					cur[n][k] += prev[n][k-n]
+				}
+				// largest value is a y
+				if k < len(cur[n-1]) {
+					cur[n][k] += cur[n-1][k]
+				}
+			}
+		}
+		prev = cur
+	}
+
+	dist := prev[n2]
+	total, tail := 0.0, 0.0
+	for k, c := range dist {
+		total += c
+		if k <= u {
+			tail += c
+		}
+	}
+
+	return math.Min(1, 2*tail/total)
+}
+
+// two-sided p-value from the normal approximation with tie and continuity corrections
+func normalP(n1, n2 int, u float64, ties []int) float64 {
+
+	n := float64(n1 + n2)
+	mu := float64(n1*n2) / 2
+
+	correction := 0.0
+	for _, t := range ties {
+		correction += float64(t*t*t - t)
+	}
+
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - correction/(n*(n-1))))
+	if sigma == 0 {
+		// every value is the same
+		return 1
+	}
+
+	z := (math.Abs(u-mu) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+
+	t.Run("empty sample", func(t *testing.T) {
+		_, _, err := MannWhitneyU([]float64{1, 2}, nil)
+		assert.Equal(t, err, ErrSampleSize)
+	})
+
+	t.Run("separated samples", func(t *testing.T) {
+		u, p, err := MannWhitneyU([]float64{1, 2, 3, 4}, []float64{5, 6, 7, 8})
+		assert.Nil(t, err)
+		assert.Equal(t, u, 0.0)
+
+		// 2 of 70 possible arrangements are as extreme
+		assert.InDelta(t, p, 2.0/70, 1e-9)
+	})
+
+	t.Run("interleaved samples", func(t *testing.T) {
+		_, p, err := MannWhitneyU([]float64{1, 4, 5, 8}, []float64{2, 3, 6, 7})
+		assert.Nil(t, err)
+		assert.Equal(t, p, 1.0)
+	})
+
+	t.Run("single samples", func(t *testing.T) {
+		_, p, err := MannWhitneyU([]float64{413}, []float64{398})
+		assert.Nil(t, err)
+		assert.Equal(t, p, 1.0)
+	})
+
+	t.Run("ties use the normal approximation", func(t *testing.T) {
+		u, p, err := MannWhitneyU([]float64{1, 2, 2, 3, 3}, []float64{3, 4, 4, 5, 6})
+		assert.Nil(t, err)
+		assert.Equal(t, u, 1.0)
+		assert.InDelta(t, p, 0.0192, 0.001)
+	})
+
+	t.Run("all values equal", func(t *testing.T) {
+		_, p, err := MannWhitneyU([]float64{1, 1, 1}, []float64{1, 1, 1})
+		assert.Nil(t, err)
+		assert.Equal(t, p, 1.0)
+	})
+}
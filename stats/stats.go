@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// two-sided 95% t-distribution critical values by degrees of freedom
+var tTable = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// Summary describes a set of samples
+type Summary struct {
+	N      int     `json:"n"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"` // sample standard deviation
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	CILow  float64 `json:"ciLow"` // 95% confidence interval of the mean
+	CIHigh float64 `json:"ciHigh"`
+}
+
+// Summarize computes the summary statistics of the samples
+func Summarize(samples []float64) Summary {
+
+	n := len(samples)
+	if n == 0 {
+		return Summary{}
+	}
+
+	sorted := make([]float64, n)
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	s := Summary{
+		N:      n,
+		Mean:   Mean(samples),
+		Median: median(sorted),
+		StdDev: StdDev(samples),
+		Min:    sorted[0],
+		Max:    sorted[n-1],
+	}
+
+	// a single sample has no spread to estimate from
+	margin := 0.0
+	if n > 1 {
+		margin = tValue(n-1) * s.StdDev / math.Sqrt(float64(n))
+	}
+	s.CILow = s.Mean - margin
+	s.CIHigh = s.Mean + margin
+
+	return s
+}
+
+// Mean returns the arithmetic mean of the samples
+func Mean(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range samples {
+		sum += v
+	}
+	return sum / float64(len(samples))
+}
+
+// StdDev returns the sample standard deviation of the samples
+func StdDev(samples []float64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	mean := Mean(samples)
+	sum := 0.0
+	for _, v := range samples {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(samples)-1))
+}
+
+// median of already sorted samples
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// critical t value for the given degrees of freedom, past the table
+// the value of the smallest df of each bucket so intervals never shrink
+func tValue(df int) float64 {
+	switch {
+	case df <= len(tTable):
+		return tTable[df-1]
+	case df <= 40:
+		return 2.042
+	case df <= 60:
+		return 2.021
+	case df <= 120:
+		return 2.000
+	default:
+		return 1.980
+	}
+}
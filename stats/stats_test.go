@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+
+	t.Run("no samples", func(t *testing.T) {
+		s := Summarize(nil)
+		assert.Equal(t, s, Summary{})
+	})
+
+	t.Run("single sample", func(t *testing.T) {
+		s := Summarize([]float64{413})
+		assert.Equal(t, s.N, 1)
+		assert.Equal(t, s.Mean, 413.0)
+		assert.Equal(t, s.Median, 413.0)
+		assert.Equal(t, s.StdDev, 0.0)
+		assert.Equal(t, s.CILow, 413.0)
+		assert.Equal(t, s.CIHigh, 413.0)
+	})
+
+	t.Run("multiple samples", func(t *testing.T) {
+		s := Summarize([]float64{4, 2, 8, 6})
+		assert.Equal(t, s.N, 4)
+		assert.Equal(t, s.Mean, 5.0)
+		assert.Equal(t, s.Median, 5.0)
+		assert.Equal(t, s.Min, 2.0)
+		assert.Equal(t, s.Max, 8.0)
+		assert.InDelta(t, s.StdDev, 2.5820, 0.0001)
+
+		// 5 ± 3.182 * 2.582 / 2
+		assert.InDelta(t, s.CILow, 0.8920, 0.0001)
+		assert.InDelta(t, s.CIHigh, 9.1080, 0.0001)
+	})
+
+	t.Run("odd number of samples", func(t *testing.T) {
+		s := Summarize([]float64{3, 1, 2})
+		assert.Equal(t, s.Median, 2.0)
+	})
+}
+
+func TestTValue(t *testing.T) {
+	assert.Equal(t, tValue(1), 12.706)
+	assert.Equal(t, tValue(30), 2.042)
+	assert.Equal(t, tValue(31), 2.042)
+	assert.Equal(t, tValue(40), 2.042)
+	assert.Equal(t, tValue(41), 2.021)
+	assert.Equal(t, tValue(61), 2.000)
+	assert.Equal(t, tValue(121), 1.980)
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter writes whole lines prefixed with a label, so the output of
+// concurrent environments sharing a terminal doesn't interleave. Spinner
+// frames, which are rewritten with a carriage return, are dropped.
+type PrefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex // shared by every writer of `w`
+	prefix string
+	line   bytes.Buffer
+}
+
+// NewPrefixWriter creates a PrefixWriter writing to `w`, `mu` must be
+// shared by every PrefixWriter of `w`
+func NewPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, mu: mu, prefix: prefix}
+}
+
+// Write buffers `p` writing every complete line
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range b {
+		switch c {
+		case '\r':
+			p.line.Reset()
+		case '\n':
+			if _, err := io.WriteString(p.w, p.prefix+p.line.String()+"\n"); err != nil {
+				return 0, err
+			}
+			p.line.Reset()
+		default:
+			p.line.WriteByte(c)
+		}
+	}
+
+	return len(b), nil
+}
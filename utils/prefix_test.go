@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+
+	var buf bytes.Buffer
+	var mu sync.Mutex
+
+	a := NewPrefixWriter(&buf, &mu, "[a] ")
+	b := NewPrefixWriter(&buf, &mu, "[b] ")
+
+	fmt.Fprintf(a, "\r  preparing image |")
+	fmt.Fprintf(b, "setting up\n")
+	fmt.Fprintf(a, "\r  preparing image done !\n")
+	fmt.Fprintf(a, "partial")
+
+	assert.Equal(t, buf.String(), "[b] setting up\n[a]   preparing image done !\n")
+}
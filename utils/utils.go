@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -14,6 +16,12 @@ import (
 
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
+// seeded once per process, parallel jobs share it so it's locked
+var (
+	randMu  sync.Mutex
+	randSrc = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
 func StripCtlAndExtFromUnicode(str string) string {
 	isOk := func(r rune) bool {
 		// skip newline char
@@ -28,11 +36,12 @@ func StripCtlAndExtFromUnicode(str string) string {
 }
 
 func RandString(n int) string {
-	rand.Seed(time.Now().UnixNano())
+	randMu.Lock()
+	defer randMu.Unlock()
 
 	b := make([]rune, n)
 	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
+		b[i] = letterRunes[randSrc.Intn(len(letterRunes))]
 	}
 	return string(b)
 }
@@ -79,6 +88,50 @@ func PrepareBeforeCommands(commands []string) []string {
 	return []string{"bash", "-c", prepared}
 }
 
+// PrepareEnv sets up the container environment variables sorted by name,
+// `pass` variables are copied from the host when set, `env` ones take precedence
+// example output
+// [GOMAXPROCS=1 HOME=/root]
+func PrepareEnv(env map[string]string, pass []string) []string {
+
+	vars := map[string]string{}
+	for _, name := range pass {
+		if v, ok := os.LookupEnv(name); ok {
+			vars[name] = v
+		}
+	}
+	for k, v := range env {
+		vars[k] = v
+	}
+
+	var prepared []string
+	for k, v := range vars {
+		prepared = append(prepared, k+"="+v)
+	}
+	sort.Strings(prepared)
+	return prepared
+}
+
+// ReportEnv lists the environment variables shown on reports and labels sorted
+// by name, `pass` variables are listed by name only, so their host values
+// aren't recorded and labels don't depend on the host running ben
+// example output
+// [GOMAXPROCS=1 NODE_OPTIONS]
+func ReportEnv(env map[string]string, pass []string) []string {
+
+	var listed []string
+	for k, v := range env {
+		listed = append(listed, k+"="+v)
+	}
+	for _, name := range pass {
+		if _, ok := env[name]; !ok {
+			listed = append(listed, name)
+		}
+	}
+	sort.Strings(listed)
+	return listed
+}
+
 func Welcome() {
 	fmt.Printf("\n\r  %s\n\n", "ben started !")
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,6 +38,39 @@ func TestPrepareImage(t *testing.T) {
 	assert.Equal(t, PrepareImage("golang", "1.4"), "golang:1.4")
 }
 
+func TestPrepareEnv(t *testing.T) {
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, len(PrepareEnv(nil, nil)), 0)
+	})
+
+	t.Run("passed from host", func(t *testing.T) {
+		os.Setenv("BEN_TEST_PASS", "host")
+		defer os.Unsetenv("BEN_TEST_PASS")
+
+		env := PrepareEnv(map[string]string{"GOMAXPROCS": "1"}, []string{"BEN_TEST_PASS", "BEN_TEST_UNSET"})
+		assert.Equal(t, env, []string{"BEN_TEST_PASS=host", "GOMAXPROCS=1"})
+	})
+
+	t.Run("env takes precedence", func(t *testing.T) {
+		os.Setenv("BEN_TEST_PASS", "host")
+		defer os.Unsetenv("BEN_TEST_PASS")
+
+		env := PrepareEnv(map[string]string{"BEN_TEST_PASS": "env"}, []string{"BEN_TEST_PASS"})
+		assert.Equal(t, env, []string{"BEN_TEST_PASS=env"})
+	})
+}
+
+func TestReportEnv(t *testing.T) {
+	os.Setenv("BEN_TEST_PASS", "secret")
+	defer os.Unsetenv("BEN_TEST_PASS")
+
+	assert.Equal(t, len(ReportEnv(nil, nil)), 0)
+
+	env := ReportEnv(map[string]string{"GOMAXPROCS": "1", "GOGC": "off"}, []string{"BEN_TEST_PASS", "GOGC"})
+	assert.Equal(t, env, []string{"BEN_TEST_PASS", "GOGC=off", "GOMAXPROCS=1"})
+}
+
 func TestPrepareBeforeCommands(t *testing.T) {
 
 	t.Run("simple single command", func(t *testing.T) {
@@ -50,3 +84,13 @@ func TestPrepareBeforeCommands(t *testing.T) {
 		assert.Equal(t, c, []string{"bash", "-c", "apt-get update && echo test && ls"})
 	})
 }
+
+func TestRandString(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		s := RandString(12)
+		assert.Equal(t, len(s), 12)
+		assert.Equal(t, seen[s], false)
+		seen[s] = true
+	}
+}
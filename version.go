@@ -0,0 +1,4 @@
+package ben
+
+// Version is the current ben version
+const Version = "0.2.0"